@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/go-github/v90/github"
+	"golang.org/x/oauth2"
+)
+
+// Lifetime of the JSON Web Tokens authenticating as a GitHub App. GitHub accepts at most 10 minutes.
+const (
+	AppJWTLifetime = 9 * time.Minute
+	// AppJWTClockSkew backdates the tokens, in case the clock of the runner is ahead of GitHub's.
+	AppJWTClockSkew = time.Minute
+)
+
+// GitHubAppCredentials holds the credentials to authenticate as an installation of a GitHub App.
+type GitHubAppCredentials struct {
+	AppID          int64
+	PrivateKey     []byte // PrivateKey is the PEM encoded private key of the app.
+	InstallationID int64
+}
+
+// NewGitHubAppClientWrapper creates a new wrapper for a GitHub client authenticated as an installation of a GitHub App.
+// Installation tokens are created with the private key of the app and renewed before they expire.
+func NewGitHubAppClientWrapper(credentials GitHubAppCredentials, gitHubEnterpriseUrl string) (*GitHubClientWrapper, error) {
+	key, err := parsePrivateKey(credentials.PrivateKey)
+	if err != nil {
+		return nil, withKind(ErrAuth, fmt.Errorf("parsing GitHub App private key: %w", err))
+	}
+
+	ctx := context.Background()
+	jwtSource := oauth2.ReuseTokenSource(nil, &appJWTSource{appID: credentials.AppID, key: key, now: time.Now})
+	appClient, err := newGitHubClient(ctx, jwtSource, gitHubEnterpriseUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	tokenSource := oauth2.ReuseTokenSource(nil, &installationTokenSource{
+		ctx:            ctx,
+		client:         appClient,
+		installationID: credentials.InstallationID,
+	})
+	client, err := newGitHubClient(ctx, tokenSource, gitHubEnterpriseUrl)
+	if err != nil {
+		return nil, err
+	}
+	return &GitHubClientWrapper{client: client, appClient: appClient}, nil
+}
+
+// installationTokenSource creates installation access tokens for a GitHub App.
+type installationTokenSource struct {
+	ctx            context.Context
+	client         *github.Client // client is authenticated as the app.
+	installationID int64
+}
+
+// Token creates a new installation access token.
+func (s *installationTokenSource) Token() (*oauth2.Token, error) {
+	token, _, err := s.client.Apps.CreateInstallationToken(s.ctx, s.installationID, nil)
+	if err != nil {
+		return nil, withKind(ErrAuth, fmt.Errorf("creating installation token: %w", err))
+	}
+	return &oauth2.Token{AccessToken: token.GetToken(), TokenType: "token", Expiry: token.GetExpiresAt().Time}, nil
+}
+
+// appJWTSource creates JSON Web Tokens authenticating as a GitHub App, signed with its private key.
+type appJWTSource struct {
+	appID int64
+	key   *rsa.PrivateKey
+	now   func() time.Time
+}
+
+// Token creates a new JSON Web Token.
+func (s *appJWTSource) Token() (*oauth2.Token, error) {
+	now := s.now()
+	issuedAt, expiresAt := now.Add(-AppJWTClockSkew), now.Add(AppJWTLifetime)
+
+	jwt, err := signJWT(s.key, map[string]any{
+		"iat": issuedAt.Unix(),
+		"exp": expiresAt.Unix(),
+		"iss": strconv.FormatInt(s.appID, 10),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &oauth2.Token{AccessToken: jwt, TokenType: "Bearer", Expiry: expiresAt}, nil
+}
+
+// signJWT creates a JSON Web Token with the given claims, signed with RS256.
+func signJWT(key *rsa.PrivateKey, claims map[string]any) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// parsePrivateKey parses a PEM encoded RSA private key in PKCS #1 or PKCS #8 format.
+// GitHub creates private keys for apps in PKCS #1 format.
+func parsePrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM encoded key found")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("key is not an RSA private key")
+	}
+	return key, nil
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v90/github"
+)
+
+// newTestKey generates an RSA private key for signing test tokens.
+func newTestKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generating key: %v", err)
+	}
+	return key
+}
+
+func TestParsePrivateKey(t *testing.T) {
+	key := newTestKey(t)
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshaling key: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr bool
+	}{
+		{"PKCS1", pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}), false},
+		{"PKCS8", pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8}), false},
+		{"NotPEM", []byte("not a key"), true},
+		{"InvalidKey", pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: []byte("invalid")}), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parsePrivateKey(tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parsePrivateKey() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !got.Equal(key) {
+				t.Errorf("parsePrivateKey() returned a different key")
+			}
+		})
+	}
+}
+
+func TestNewGitHubAppClientWrapperInvalidKey(t *testing.T) {
+	_, err := NewGitHubAppClientWrapper(GitHubAppCredentials{AppID: 1, PrivateKey: []byte("not a key"), InstallationID: 2}, "")
+	if !errors.Is(err, ErrAuth) {
+		t.Errorf("NewGitHubAppClientWrapper() error = %v, want an authentication error", err)
+	}
+}
+
+func TestAppJWTSource(t *testing.T) {
+	key := newTestKey(t)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	source := &appJWTSource{appID: 42, key: key, now: func() time.Time { return now }}
+
+	token, err := source.Token()
+	if err != nil {
+		t.Fatalf("Token() error = %v", err)
+	}
+	if !token.Expiry.Equal(now.Add(AppJWTLifetime)) {
+		t.Errorf("Token() expiry = %v, want %v", token.Expiry, now.Add(AppJWTLifetime))
+	}
+
+	parts := strings.Split(token.AccessToken, ".")
+	if len(parts) != 3 {
+		t.Fatalf("Token() = %q, want a JSON Web Token with three parts", token.AccessToken)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		t.Fatalf("decoding signature: %v", err)
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], signature); err != nil {
+		t.Errorf("verifying signature: %v", err)
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		t.Fatalf("decoding claims: %v", err)
+	}
+	var claims struct {
+		IssuedAt  int64  `json:"iat"`
+		ExpiresAt int64  `json:"exp"`
+		Issuer    string `json:"iss"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		t.Fatalf("parsing claims: %v", err)
+	}
+	if claims.Issuer != "42" || claims.IssuedAt != now.Add(-AppJWTClockSkew).Unix() || claims.ExpiresAt != now.Add(AppJWTLifetime).Unix() {
+		t.Errorf("Token() claims = %+v, want the app ID as issuer and the issue and expiry times", claims)
+	}
+}
+
+func TestInstallationTokenSource(t *testing.T) {
+	expiresAt := time.Date(2024, 1, 1, 13, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		statusCode int
+		wantErr    error
+	}{
+		{"Created", http.StatusCreated, nil},
+		{"BadCredentials", http.StatusUnauthorized, ErrAuth},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPost || r.URL.Path != "/app/installations/7/access_tokens" {
+					t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+				}
+				w.WriteHeader(tt.statusCode)
+				if tt.statusCode == http.StatusCreated {
+					_ = json.NewEncoder(w).Encode(map[string]string{"token": "installation-token", "expires_at": expiresAt.Format(time.RFC3339)})
+				}
+			}))
+			defer server.Close()
+
+			serverURL := server.URL + "/"
+			client, err := github.NewClient(github.WithURLs(&serverURL, &serverURL))
+			if err != nil {
+				t.Fatalf("creating client: %v", err)
+			}
+			source := &installationTokenSource{ctx: t.Context(), client: client, installationID: 7}
+
+			token, err := source.Token()
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Token() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if token.AccessToken != "installation-token" || !token.Expiry.Equal(expiresAt) {
+				t.Errorf("Token() = %q expiring %v, want %q expiring %v", token.AccessToken, token.Expiry, "installation-token", expiresAt)
+			}
+		})
+	}
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v90/github"
+)
+
+// Events that relabel all open pull requests of the repository instead of a single one.
+const (
+	EventSchedule         = "schedule"
+	EventWorkflowDispatch = "workflow_dispatch"
+)
+
+const (
+	// DefaultBatchConcurrency is the number of pull requests processed at the same time in batch mode.
+	DefaultBatchConcurrency = 4
+	// PullRequestsPerPage is the page size used when listing open pull requests.
+	PullRequestsPerPage = 100
+	// MinRemainingRequests is the number of remaining API requests below which batch mode waits for the rate limit to reset.
+	MinRemainingRequests = 100
+	// SecondaryRateLimitWait is the time to wait after hitting a secondary rate limit without a Retry-After header.
+	SecondaryRateLimitWait = time.Minute
+)
+
+// isBatchEvent checks if the event can relabel all open pull requests.
+func isBatchEvent(eventName string) bool {
+	switch strings.ToLower(eventName) {
+	case EventSchedule, EventWorkflowDispatch:
+		return true
+	}
+	return false
+}
+
+// isBatchRun checks if all open pull requests are relabeled, which is the case for batch events without a pull request number.
+func (args EnvArgs) isBatchRun() bool {
+	return args.PrNumber == "" && isBatchEvent(args.EventName)
+}
+
+// BatchProcessor handles the processing of all open pull requests of a repository.
+type BatchProcessor struct {
+	clientWrapper *GitHubClientWrapper
+	repoOwner     string
+	repoName      string
+	config        Config
+	dryRun        bool
+	concurrency   int
+	ctx           context.Context
+}
+
+// NewBatchProcessor creates a new BatchProcessor instance.
+// At most concurrency pull requests are processed at the same time, or DefaultBatchConcurrency if it is not positive.
+func NewBatchProcessor(ctx context.Context, clientWrapper *GitHubClientWrapper, repoOwner, repoName string, config Config, dryRun bool, concurrency int) *BatchProcessor {
+	if concurrency < 1 {
+		concurrency = DefaultBatchConcurrency
+	}
+	return &BatchProcessor{
+		clientWrapper: clientWrapper,
+		repoOwner:     repoOwner,
+		repoName:      repoName,
+		config:        config,
+		dryRun:        dryRun,
+		concurrency:   concurrency,
+		ctx:           ctx,
+	}
+}
+
+// ProcessOpenPullRequests processes all open pull requests of the repository.
+// A failing pull request does not stop the others, all errors are returned together.
+func (bp *BatchProcessor) ProcessOpenPullRequests() error {
+	prNumbers, err := bp.listOpenPullRequests()
+	if err != nil {
+		return fmt.Errorf("listing open pull requests: %w", err)
+	}
+	logger.Infof("Processing %d open pull requests", len(prNumbers))
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+	sem := make(chan struct{}, bp.concurrency)
+	for _, prNumber := range prNumbers {
+		sem <- struct{}{}
+		wg.Go(func() {
+			defer func() { <-sem }()
+			if err := bp.processPullRequest(prNumber); err != nil {
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("pull request #%d: %w", prNumber, err))
+				mu.Unlock()
+			}
+		})
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// processPullRequest processes a single pull request, waiting for the rate limit if needed.
+// A pull request failing because of a rate limit is retried once after the limit resets.
+// The log lines of the pull request are written as one group once it is done.
+func (bp *BatchProcessor) processPullRequest(prNumber int) error {
+	if err := bp.waitForRateLimit(); err != nil {
+		return err
+	}
+
+	log, flush := logger.BufferedGroup(fmt.Sprintf("Pull request #%d", prNumber))
+	defer flush()
+
+	prProcessor := NewPullRequestProcessor(bp.ctx, bp.clientWrapper, bp.repoOwner, bp.repoName, prNumber, bp.config, bp.dryRun)
+	prProcessor.log = log
+	result, err := prProcessor.ProcessPullRequest()
+	if wait, ok := rateLimitRetryAfter(err, time.Now()); ok {
+		log.Infof("Rate limit exceeded while processing pull request #%d, retrying in %s", prNumber, wait)
+		if err := sleepContext(bp.ctx, wait); err != nil {
+			return err
+		}
+		result, err = prProcessor.ProcessPullRequest()
+	}
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Pull request #%d has size %s", prNumber, result.Entry.Size)
+	return nil
+}
+
+// listOpenPullRequests returns the numbers of all open pull requests, following pagination.
+func (bp *BatchProcessor) listOpenPullRequests() ([]int, error) {
+	var prNumbers []int
+	opts := &github.PullRequestListOptions{State: "open", ListOptions: github.ListOptions{PerPage: PullRequestsPerPage}}
+	for {
+		prs, resp, err := bp.clientWrapper.client.PullRequests.List(bp.ctx, bp.repoOwner, bp.repoName, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, pr := range prs {
+			prNumbers = append(prNumbers, pr.GetNumber())
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return prNumbers, nil
+}
+
+// waitForRateLimit waits for the rate limit to reset if only a few API requests are remaining.
+// Servers with rate limiting disabled, such as some GitHub Enterprise instances, are not waited for.
+func (bp *BatchProcessor) waitForRateLimit() error {
+	limits, resp, err := bp.clientWrapper.client.RateLimit.Get(bp.ctx)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return nil
+		}
+		return fmt.Errorf("fetching rate limit: %w", err)
+	}
+
+	wait := rateLimitWait(limits.GetCore(), time.Now())
+	if wait <= 0 {
+		return nil
+	}
+	logger.Infof("Only %d API requests remaining, waiting %s for the rate limit to reset", limits.GetCore().Remaining, wait)
+	return sleepContext(bp.ctx, wait)
+}
+
+// rateLimitWait returns how long to wait before the next request, based on the remaining requests.
+func rateLimitWait(rate *github.Rate, now time.Time) time.Duration {
+	if rate == nil || rate.Remaining >= MinRemainingRequests {
+		return 0
+	}
+	return max(rate.Reset.Sub(now), 0)
+}
+
+// rateLimitRetryAfter checks if the error is caused by a rate limit and returns how long to wait before retrying.
+func rateLimitRetryAfter(err error, now time.Time) (time.Duration, bool) {
+	var rateLimitErr *github.RateLimitError
+	if errors.As(err, &rateLimitErr) {
+		return max(rateLimitErr.Rate.Reset.Sub(now), 0), true
+	}
+
+	var abuseErr *github.AbuseRateLimitError
+	if errors.As(err, &abuseErr) {
+		if retryAfter := abuseErr.GetRetryAfter(); retryAfter > 0 {
+			return retryAfter, true
+		}
+		return SecondaryRateLimitWait, true
+	}
+	return 0, false
+}
+
+// sleepContext waits for the given duration or until the context is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v90/github"
+)
+
+func TestIsBatchEvent(t *testing.T) {
+	tests := []struct {
+		eventName string
+		want      bool
+	}{
+		{"schedule", true},
+		{"workflow_dispatch", true},
+		{"Workflow_Dispatch", true},
+		{"pull_request", false},
+		{"push", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.eventName, func(t *testing.T) {
+			if got := isBatchEvent(tt.eventName); got != tt.want {
+				t.Errorf("isBatchEvent(%v) = %v, want %v", tt.eventName, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsBatchRun(t *testing.T) {
+	tests := []struct {
+		name string
+		args EnvArgs
+		want bool
+	}{
+		{"Schedule", EnvArgs{EventName: "schedule"}, true},
+		{"WorkflowDispatch", EnvArgs{EventName: "workflow_dispatch"}, true},
+		{"WorkflowDispatchWithPullRequestNumber", EnvArgs{EventName: "workflow_dispatch", PrNumber: "1"}, false},
+		{"PullRequest", EnvArgs{EventName: "pull_request", PrNumber: "1"}, false},
+		{"WorkflowRunWithPullRequestNumber", EnvArgs{EventName: "workflow_run", PrNumber: "1"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.args.isBatchRun(); got != tt.want {
+				t.Errorf("isBatchRun() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMissingRequiredEnv(t *testing.T) {
+	tests := []struct {
+		name string
+		args EnvArgs
+		want []string
+	}{
+		{"AllSet", EnvArgs{GithubToken: "token", EventName: "pull_request", PrNumber: "1", RepoName: "owner/repo"}, nil},
+		{"PullRequestNumberMissing", EnvArgs{GithubToken: "token", EventName: "pull_request", RepoName: "owner/repo"}, []string{"PULL_REQUEST_NUMBER"}},
+		{"BatchEventWithoutPullRequestNumber", EnvArgs{GithubToken: "token", EventName: "schedule", RepoName: "owner/repo"}, nil},
+		{"GitHubApp", EnvArgs{GitHubAppID: "1", GitHubAppPrivateKey: "key", GitHubAppInstallationID: "2", EventName: "pull_request", PrNumber: "1", RepoName: "owner/repo"}, nil},
+		{"GitHubAppIncomplete", EnvArgs{GithubToken: "token", GitHubAppID: "1", EventName: "pull_request", PrNumber: "1", RepoName: "owner/repo"}, []string{"GITHUB_APP_PRIVATE_KEY", "GITHUB_APP_INSTALLATION_ID"}},
+		{"NothingSet", EnvArgs{}, []string{"GITHUB_TOKEN", "GITHUB_EVENT_NAME", "PULL_REQUEST_NUMBER", "GITHUB_REPOSITORY"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.args.missingRequiredEnv(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("missingRequiredEnv() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRateLimitWait(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	reset := github.Timestamp{Time: now.Add(10 * time.Minute)}
+
+	tests := []struct {
+		name string
+		rate *github.Rate
+		want time.Duration
+	}{
+		{"NoRate", nil, 0},
+		{"EnoughRemaining", &github.Rate{Remaining: 4000, Reset: reset}, 0},
+		{"FewRemaining", &github.Rate{Remaining: 10, Reset: reset}, 10 * time.Minute},
+		{"ResetInThePast", &github.Rate{Remaining: 10, Reset: github.Timestamp{Time: now.Add(-time.Minute)}}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rateLimitWait(tt.rate, now); got != tt.want {
+				t.Errorf("rateLimitWait() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRateLimitRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	retryAfter := 30 * time.Second
+
+	tests := []struct {
+		name     string
+		err      error
+		wantWait time.Duration
+		wantOk   bool
+	}{
+		{"NoError", nil, 0, false},
+		{"OtherError", errors.New("not found"), 0, false},
+		{"PrimaryRateLimit", fmt.Errorf("fetching pull request: %w", &github.RateLimitError{Rate: github.Rate{Reset: github.Timestamp{Time: now.Add(5 * time.Minute)}}}), 5 * time.Minute, true},
+		{"SecondaryRateLimitWithRetryAfter", &github.AbuseRateLimitError{RetryAfter: &retryAfter}, retryAfter, true},
+		{"SecondaryRateLimitWithoutRetryAfter", &github.AbuseRateLimitError{}, SecondaryRateLimitWait, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotWait, gotOk := rateLimitRetryAfter(tt.err, now)
+			if gotWait != tt.wantWait || gotOk != tt.wantOk {
+				t.Errorf("rateLimitRetryAfter() = %v, %v, want %v, %v", gotWait, gotOk, tt.wantWait, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestNewBatchProcessorConcurrency(t *testing.T) {
+	tests := []struct {
+		name        string
+		concurrency int
+		want        int
+	}{
+		{"Set", 8, 8},
+		{"Unset", 0, DefaultBatchConcurrency},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bp := NewBatchProcessor(t.Context(), nil, "owner", "repo", Config{}, false, tt.concurrency)
+			if bp.concurrency != tt.want {
+				t.Errorf("NewBatchProcessor() concurrency = %d, want %d", bp.concurrency, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/cbrgm/pr-size-labeler-action/pkg/sizer"
+	"github.com/google/go-github/v90/github"
+)
+
+// SizeCommentMarker is a hidden marker identifying the sticky size comment.
+const SizeCommentMarker = "<!-- pr-size-labeler-action:size-comment -->"
+
+// GitHubActionsLogin is the login of the user commenting with the token of GitHub Actions.
+const GitHubActionsLogin = "github-actions[bot]"
+
+// updateSizeComment creates the sticky size comment or edits it if it already exists.
+func (prp *PullRequestProcessor) updateSizeComment(result sizer.Result) error {
+	body := renderSizeComment(result, prp.config.LabelConfigs)
+
+	comment, err := prp.findSizeComment()
+	if err != nil {
+		return err
+	}
+
+	if comment == nil {
+		_, _, err = prp.clientWrapper.client.Issues.CreateComment(prp.ctx, prp.repoOwner, prp.repoName, prp.prNumber, &github.IssueComment{Body: &body})
+		return err
+	}
+
+	if comment.GetBody() == body {
+		return nil
+	}
+	_, _, err = prp.clientWrapper.client.Issues.EditComment(prp.ctx, prp.repoOwner, prp.repoName, comment.GetID(), &github.IssueComment{Body: &body})
+	return err
+}
+
+// findSizeComment finds the sticky size comment on the pull request, following pagination.
+// It returns nil if the comment does not exist yet.
+// Only comments written by the authenticated identity count, so quoting the comment does not take it over.
+func (prp *PullRequestProcessor) findSizeComment() (*github.IssueComment, error) {
+	login, err := prp.clientWrapper.authenticatedLogin(prp.ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := &github.IssueListCommentsOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		comments, resp, err := prp.clientWrapper.client.Issues.ListComments(prp.ctx, prp.repoOwner, prp.repoName, prp.prNumber, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, comment := range comments {
+			if isSizeComment(comment, login) {
+				return comment, nil
+			}
+		}
+		if resp.NextPage == 0 {
+			return nil, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// isSizeComment checks if a comment is the sticky size comment written by the given login.
+func isSizeComment(comment *github.IssueComment, login string) bool {
+	return comment.GetUser().GetLogin() == login && strings.Contains(comment.GetBody(), SizeCommentMarker)
+}
+
+// authenticatedLogin returns the login of the identity the client is authenticated as, looked up once.
+// GitHub Apps comment as their bot user. The token of GitHub Actions can not read its own user, so a
+// forbidden lookup means the client comments as GitHubActionsLogin.
+func (cw *GitHubClientWrapper) authenticatedLogin(ctx context.Context) (string, error) {
+	cw.loginOnce.Do(func() {
+		cw.login, cw.loginErr = cw.lookupLogin(ctx)
+	})
+	return cw.login, cw.loginErr
+}
+
+// lookupLogin looks up the login of the identity the client is authenticated as.
+func (cw *GitHubClientWrapper) lookupLogin(ctx context.Context) (string, error) {
+	if cw.appClient != nil {
+		app, _, err := cw.appClient.Apps.Get(ctx, "")
+		if err != nil {
+			return "", fmt.Errorf("getting authenticated app: %w", err)
+		}
+		return app.GetSlug() + "[bot]", nil
+	}
+
+	user, _, err := cw.client.Users.Get(ctx, "")
+	var errResp *github.ErrorResponse
+	if errors.As(err, &errResp) && errResp.Response != nil && errResp.Response.StatusCode == http.StatusForbidden {
+		return GitHubActionsLogin, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("getting authenticated user: %w", err)
+	}
+	return user.GetLogin(), nil
+}
+
+// renderSizeComment renders the body of the sticky size comment.
+func renderSizeComment(result sizer.Result, labelConfigs []sizer.ConfigEntry) string {
+	return SizeCommentMarker + "\n" + renderSizeReport(result, labelConfigs)
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cbrgm/pr-size-labeler-action/pkg/sizer"
+	"github.com/google/go-github/v90/github"
+)
+
+func TestIsSizeComment(t *testing.T) {
+	bot := &github.User{Login: github.Ptr(GitHubActionsLogin)}
+	tests := []struct {
+		name    string
+		comment *github.IssueComment
+		want    bool
+	}{
+		{"SizeComment", &github.IssueComment{User: bot, Body: github.Ptr(SizeCommentMarker + "\n### Pull request size")}, true},
+		{"QuotedByOtherUser", &github.IssueComment{User: &github.User{Login: github.Ptr("octocat")}, Body: github.Ptr("> " + SizeCommentMarker)}, false},
+		{"OtherComment", &github.IssueComment{User: bot, Body: github.Ptr("LGTM")}, false},
+		{"EmptyComment", &github.IssueComment{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isSizeComment(tt.comment, GitHubActionsLogin); got != tt.want {
+				t.Errorf("isSizeComment() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderSizeComment(t *testing.T) {
+	result := sizer.Result{FilesEntry: reportLabelConfigs[0], DiffEntry: reportLabelConfigs[0], Entry: reportLabelConfigs[0]}
+
+	got := renderSizeComment(result, reportLabelConfigs)
+	if !strings.HasPrefix(got, SizeCommentMarker) {
+		t.Errorf("renderSizeComment() = %q, want it to start with the marker", got)
+	}
+	if !isSizeComment(&github.IssueComment{User: &github.User{Login: github.Ptr(GitHubActionsLogin)}, Body: &got}, GitHubActionsLogin) {
+		t.Errorf("renderSizeComment() is not recognized by isSizeComment()")
+	}
+}
+
+func TestAuthenticatedLogin(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		want   string
+	}{
+		{"User", http.StatusOK, "octocat"},
+		{"GitHubActionsToken", http.StatusForbidden, GitHubActionsLogin},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				w.WriteHeader(tt.status)
+				_ = json.NewEncoder(w).Encode(map[string]string{"login": "octocat", "message": "Resource not accessible by integration"})
+			}))
+			defer server.Close()
+
+			serverURL := server.URL + "/"
+			client, err := github.NewClient(github.WithURLs(&serverURL, &serverURL))
+			if err != nil {
+				t.Fatalf("creating client: %v", err)
+			}
+			cw := &GitHubClientWrapper{client: client}
+
+			for range 2 {
+				got, err := cw.authenticatedLogin(t.Context())
+				if err != nil || got != tt.want {
+					t.Errorf("authenticatedLogin() = %q, %v, want %q", got, err, tt.want)
+				}
+			}
+			if requests != 1 {
+				t.Errorf("authenticatedLogin() made %d requests, want 1", requests)
+			}
+		})
+	}
+}
@@ -0,0 +1,422 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/cbrgm/pr-size-labeler-action/pkg/sizer"
+	"gopkg.in/yaml.v3"
+)
+
+// ErrNoLabelConfigs is returned for a configuration without any size entries.
+var ErrNoLabelConfigs = errors.New("label_configs must define at least one size")
+
+// ConfigError describes a single problem of a configuration file.
+type ConfigError struct {
+	Line int // Line is the line number of the problem, or 0 if it is unknown.
+	Err  error
+}
+
+// Error returns the problem prefixed with its line number.
+func (e ConfigError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+	}
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e ConfigError) Unwrap() error {
+	return e.Err
+}
+
+// ConfigErrors holds every problem found in a configuration file.
+type ConfigErrors []ConfigError
+
+// Error returns all problems, one per line.
+func (e ConfigErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "\n")
+}
+
+// Unwrap returns the individual problems.
+func (e ConfigErrors) Unwrap() []error {
+	errs := make([]error, 0, len(e))
+	for _, err := range e {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// parseConfigFile parses and validates the configuration read from filePath.
+// Every problem is logged as an error pointing at its line, the returned error summarizes them.
+func parseConfigFile(filePath string, data []byte, fetcher ConfigFetcher) (Config, error) {
+	config, err := parseConfig(data, fetcher)
+	var configErrs ConfigErrors
+	if errors.As(err, &configErrs) {
+		for _, configErr := range configErrs {
+			logger.ErrorAt(filePath, configErr.Line, "%v", configErr.Err)
+		}
+		return config, withKind(ErrConfig, fmt.Errorf("%s has %d problems", filePath, len(configErrs)))
+	}
+	return config, err
+}
+
+// parseConfig parses and validates the configuration from YAML.
+// Unknown fields are rejected, so typos do not go unnoticed.
+// The shared configuration it extends is fetched with fetcher and merged with it before it is validated.
+func parseConfig(data []byte, fetcher ConfigFetcher) (Config, error) {
+	config, root, errs, err := decodeConfig(data)
+	if err != nil {
+		return config, err
+	}
+
+	if config.Extends != "" {
+		base, err := resolveExtends(fetcher, config.Extends, nil)
+		if errors.Is(err, ErrConfig) {
+			return config, append(errs, ConfigError{Line: config.line("extends"), Err: err})
+		}
+		if err != nil {
+			return config, err
+		}
+
+		root = mergeConfigNodes(base, root)
+		config = Config{}
+		if err := root.Decode(&config); err != nil {
+			return config, append(errs, newYAMLConfigErrors(err)...)
+		}
+		config.node = root
+	}
+
+	var validationErrs ConfigErrors
+	if errors.As(config.Validate(), &validationErrs) {
+		errs = append(errs, validationErrs...)
+	}
+
+	if len(errs) > 0 {
+		return config, errs
+	}
+	return config, nil
+}
+
+// decodeConfig decodes the configuration from YAML without validating it and returns its YAML document.
+// Unknown fields and type mismatches are returned as configuration errors, so they are reported
+// together with the problems found by Validate. Syntax errors are returned as error.
+func decodeConfig(data []byte) (Config, *yaml.Node, ConfigErrors, error) {
+	var config Config
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return config, nil, nil, newYAMLConfigErrors(err)
+	}
+
+	var errs ConfigErrors
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&config); err != nil && !errors.Is(err, io.EOF) {
+		var typeErr *yaml.TypeError
+		if !errors.As(err, &typeErr) {
+			return config, nil, nil, newYAMLConfigErrors(err)
+		}
+		errs = newYAMLConfigErrors(err)
+	}
+
+	config.node = &root
+	return config, &root, errs, nil
+}
+
+// yamlLinePrefix matches the line number prefix of errors reported by the YAML decoder.
+var yamlLinePrefix = regexp.MustCompile(`^(?:yaml: )?line (\d+): `)
+
+// newYAMLConfigErrors converts an error of the YAML decoder into configuration errors with line numbers.
+func newYAMLConfigErrors(err error) ConfigErrors {
+	messages := []string{err.Error()}
+	var typeErr *yaml.TypeError
+	if errors.As(err, &typeErr) {
+		messages = typeErr.Errors
+	}
+
+	errs := make(ConfigErrors, 0, len(messages))
+	for _, message := range messages {
+		var line int
+		if match := yamlLinePrefix.FindStringSubmatch(message); match != nil {
+			line, _ = strconv.Atoi(match[1])
+			message = message[len(match[0]):]
+		}
+		errs = append(errs, ConfigError{Line: line, Err: errors.New(message)})
+	}
+	return errs
+}
+
+// Validate checks the configuration for problems and reports all of them with their line numbers.
+func (c Config) Validate() error {
+	var errs ConfigErrors
+	errs = append(errs, c.validateLabelConfigs()...)
+	errs = append(errs, c.validatePatterns()...)
+	errs = append(errs, c.validatePathLabels()...)
+	errs = append(errs, c.validateScopes()...)
+
+	if c.FailAbove != "" && sizer.FindConfigEntryIndex(c.LabelConfigs, c.FailAbove) < 0 {
+		errs = append(errs, newConfigError(c.line("fail_above"), "fail_above references unknown size %q", c.FailAbove))
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// validateLabelConfigs checks that sizes are unique, labels are set and thresholds increase monotonically.
+func (c Config) validateLabelConfigs() ConfigErrors {
+	if len(c.LabelConfigs) == 0 {
+		return ConfigErrors{{Line: c.line("label_configs"), Err: ErrNoLabelConfigs}}
+	}
+	return c.validateLadder(c.LabelConfigs, "label_configs")
+}
+
+// validateLadder checks a ladder of sizes found at the given path of the YAML document.
+func (c Config) validateLadder(labelConfigs []sizer.ConfigEntry, path ...any) ConfigErrors {
+	name := formatPath(path)
+	var errs ConfigErrors
+	sizes := make(map[string]int)
+	for i, entry := range labelConfigs {
+		line := func(elements ...any) int {
+			return c.line(slices.Concat(path, []any{i}, elements)...)
+		}
+
+		if previous, exists := sizes[entry.Size]; exists {
+			errs = append(errs, newConfigError(line("size"), "%s[%d]: size %q is already defined by %s[%d]", name, i, entry.Size, name, previous))
+		} else if entry.Size == "" {
+			errs = append(errs, newConfigError(line(), "%s[%d]: size must not be empty", name, i))
+		}
+		sizes[entry.Size] = i
+
+		for _, paramName := range []string{sizer.ParamNameFiles, sizer.ParamNameDiff} {
+			if !c.has(slices.Concat(path, []any{i, paramName})...) {
+				errs = append(errs, newConfigError(line(), "%s[%d]: %s must be set, as an unset threshold of 0 only fits pull requests without changes", name, i, paramName))
+			}
+		}
+
+		if len(entry.Labels) == 0 {
+			errs = append(errs, newConfigError(line(), "%s[%d]: labels must not be empty", name, i))
+		}
+		for j, label := range entry.Labels {
+			if strings.TrimSpace(label) == "" {
+				errs = append(errs, newConfigError(line("labels", j), "%s[%d]: labels must not contain an empty label", name, i))
+			}
+		}
+
+		if entry.Color != "" && !labelColorPattern.MatchString(entry.Color) {
+			errs = append(errs, newConfigError(line("color"), "%s[%d]: color %q must be a hex color such as \"0e8a16\"", name, i, entry.Color))
+		}
+
+		if entry.Match != "" && entry.Match != sizer.MatchAny && entry.Match != sizer.MatchAll {
+			errs = append(errs, newConfigError(line("match"), "%s[%d]: match %q must be %q or %q", name, i, entry.Match, sizer.MatchAny, sizer.MatchAll))
+		}
+		if entry.When != "" {
+			if _, err := sizer.CompileWhen(entry.When); err != nil {
+				errs = append(errs, newConfigError(line("when"), "%s[%d]: invalid when expression: %v", name, i, err))
+			}
+		}
+		errs = append(errs, validateThresholds(labelConfigs, i, name, line)...)
+	}
+	return errs
+}
+
+// validateThresholds checks that the thresholds of a size are not negative and not lower than those of the previous size.
+// Optional thresholds are only compared if both sizes set them.
+func validateThresholds(labelConfigs []sizer.ConfigEntry, i int, name string, line func(elements ...any) int) ConfigErrors {
+	var errs ConfigErrors
+	entry := labelConfigs[i]
+	for _, paramName := range sizer.ParamNames {
+		threshold, ok := entry.Threshold(paramName)
+		if !ok {
+			continue
+		}
+		if threshold < 0 {
+			errs = append(errs, newConfigError(line(paramName), "%s[%d]: %s must not be negative", name, i, paramName))
+		}
+		if i == 0 {
+			continue
+		}
+
+		previous := labelConfigs[i-1]
+		if previousThreshold, ok := previous.Threshold(paramName); ok && threshold < previousThreshold {
+			errs = append(errs, newConfigError(line(paramName), "%s[%d]: %s %d must not be lower than %s %d of size %q", name, i, paramName, threshold, paramName, previousThreshold, previous.Size))
+		}
+	}
+	return errs
+}
+
+// validateScopes checks that scopes have a unique name and a path, and that their sizes are valid.
+// Scopes without sizes of their own use the sizes of the configuration, which are checked already.
+func (c Config) validateScopes() ConfigErrors {
+	var errs ConfigErrors
+	names := make(map[string]int)
+	for i, scope := range c.Scopes {
+		if previous, exists := names[scope.Name]; exists {
+			errs = append(errs, newConfigError(c.line("scopes", i, "name"), "scopes[%d]: name %q is already used by scopes[%d]", i, scope.Name, previous))
+		} else if strings.TrimSpace(scope.Name) == "" {
+			errs = append(errs, newConfigError(c.line("scopes", i), "scopes[%d]: name must not be empty", i))
+		}
+		names[scope.Name] = i
+
+		if strings.Trim(scope.Path, "/ ") == "" {
+			errs = append(errs, newConfigError(c.line("scopes", i), "scopes[%d]: path must not be empty", i))
+		}
+
+		labelConfigs := c.ScopeConfig(scope).LabelConfigs
+		if len(scope.LabelConfigs) > 0 {
+			errs = append(errs, c.validateLadder(labelConfigs, "scopes", i, "label_configs")...)
+		}
+		for _, entry := range labelConfigs {
+			for _, label := range entry.Labels {
+				if isSizeLabel(label, c.LabelConfigs) {
+					errs = append(errs, newConfigError(c.line("scopes", i), "scopes[%d]: label %q is already a size label", i, label))
+				}
+			}
+		}
+	}
+	return errs
+}
+
+// formatPath formats a path of the YAML document for messages, such as "scopes[0].label_configs".
+func formatPath(path []any) string {
+	var sb strings.Builder
+	for _, element := range path {
+		switch element := element.(type) {
+		case int:
+			fmt.Fprintf(&sb, "[%d]", element)
+		default:
+			if sb.Len() > 0 {
+				sb.WriteString(".")
+			}
+			fmt.Fprint(&sb, element)
+		}
+	}
+	return sb.String()
+}
+
+// validatePatterns checks the exclude and weight patterns.
+func (c Config) validatePatterns() ConfigErrors {
+	var errs ConfigErrors
+	for i, pattern := range c.ExcludeFiles {
+		if !sizer.ValidatePattern(pattern) {
+			errs = append(errs, newConfigError(c.line("exclude_files", i), "exclude_files[%d]: invalid pattern %q", i, pattern))
+		}
+	}
+
+	for i, rule := range c.Weights {
+		if !sizer.ValidatePattern(rule.Pattern) {
+			errs = append(errs, newConfigError(c.line("weights", i), "weights: invalid pattern %q", rule.Pattern))
+		}
+		if rule.Weight < 0 {
+			errs = append(errs, newConfigError(c.line("weights", i), "weights: weight %v of pattern %q must not be negative", rule.Weight, rule.Pattern))
+		}
+	}
+	return errs
+}
+
+// validatePathLabels checks that path labels have a label and valid paths and are not size labels at the same time.
+func (c Config) validatePathLabels() ConfigErrors {
+	var errs ConfigErrors
+	for i, pathLabel := range c.PathLabels {
+		if strings.TrimSpace(pathLabel.Label) == "" {
+			errs = append(errs, newConfigError(c.line("path_labels", i), "path_labels[%d]: label must not be empty", i))
+		} else if isSizeLabel(pathLabel.Label, c.LabelConfigs) {
+			errs = append(errs, newConfigError(c.line("path_labels", i, "label"), "path_labels[%d]: label %q is already a size label", i, pathLabel.Label))
+		}
+
+		if len(pathLabel.Paths) == 0 {
+			errs = append(errs, newConfigError(c.line("path_labels", i), "path_labels[%d]: paths must not be empty", i))
+		}
+		for j, pattern := range pathLabel.Paths {
+			if !sizer.ValidatePattern(pattern) {
+				errs = append(errs, newConfigError(c.line("path_labels", i, "paths", j), "path_labels[%d]: invalid pattern %q", i, pattern))
+			}
+		}
+
+		if pathLabel.MinLines < 0 {
+			errs = append(errs, newConfigError(c.line("path_labels", i, "min_lines"), "path_labels[%d]: min_lines must not be negative", i))
+		}
+	}
+	return errs
+}
+
+// newConfigError creates a configuration error for the given line.
+func newConfigError(line int, format string, args ...any) ConfigError {
+	return ConfigError{Line: line, Err: fmt.Errorf(format, args...)}
+}
+
+// line returns the line number of the node at the given path in the parsed YAML document, or 0 if it is unknown.
+// A path element is either a mapping key or an index. An index into a mapping selects the key at that position.
+func (c Config) line(path ...any) int {
+	if c.node == nil || len(c.node.Content) == 0 {
+		return 0
+	}
+
+	node := c.node.Content[0]
+	line := node.Line
+	for _, element := range path {
+		node = childNode(node, element)
+		if node == nil {
+			return line
+		}
+		// Nodes of a shared configuration have no line, the line of their closest local parent is used
+		if node.Line > 0 {
+			line = node.Line
+		}
+	}
+	return line
+}
+
+// has checks if the parsed YAML document has a node at the given path.
+// Configurations that were not parsed from YAML, such as the default configuration, have every node.
+func (c Config) has(path ...any) bool {
+	if c.node == nil || len(c.node.Content) == 0 {
+		return true
+	}
+
+	node := c.node.Content[0]
+	for _, element := range path {
+		if node = childNode(node, element); node == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// childNode returns the child of a mapping or sequence node selected by a key or an index.
+func childNode(node *yaml.Node, element any) *yaml.Node {
+	switch element := element.(type) {
+	case string:
+		if node.Kind != yaml.MappingNode {
+			return nil
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == element {
+				return node.Content[i+1]
+			}
+		}
+	case int:
+		switch node.Kind {
+		case yaml.SequenceNode:
+			if element < len(node.Content) {
+				return node.Content[element]
+			}
+		case yaml.MappingNode:
+			if 2*element < len(node.Content) {
+				return node.Content[2*element]
+			}
+		}
+	}
+	return nil
+}
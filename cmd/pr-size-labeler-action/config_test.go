@@ -0,0 +1,155 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/cbrgm/pr-size-labeler-action/pkg/sizer"
+)
+
+func TestParseConfigErrors(t *testing.T) {
+	data := []byte(`lable_configs: []
+exclude_files: ["[abc"]
+weights: [docs]
+label_configs:
+  - size: xs
+    diff: 100
+    files: 1
+    labels: ["size/xs"]
+  - size: xs
+    diff: 50
+    files: 10
+    lables: ["size/s"]
+  - size: m
+    diff: 600
+    files: 5
+    labels: [""]
+fail_above: xxl
+`)
+
+	_, err := parseConfig(data, nil)
+	var configErrs ConfigErrors
+	if !errors.As(err, &configErrs) {
+		t.Fatalf("parseConfig() error = %v, want ConfigErrors", err)
+	}
+
+	var gotLines []int
+	for _, configErr := range configErrs {
+		gotLines = append(gotLines, configErr.Line)
+	}
+	wantLines := []int{1, 3, 12, 9, 9, 10, 16, 15, 2, 17}
+	if !reflect.DeepEqual(gotLines, wantLines) {
+		t.Errorf("parseConfig() reported lines %v, want %v\n%v", gotLines, wantLines, err)
+	}
+}
+
+func TestParseConfigSyntaxError(t *testing.T) {
+	_, err := parseConfig([]byte("label_configs:\n  - size: xs\n   diff: 10\n"), nil)
+	var configErrs ConfigErrors
+	if !errors.As(err, &configErrs) || len(configErrs) != 1 || configErrs[0].Line == 0 {
+		t.Errorf("parseConfig() error = %#v, want a single error with a line number", err)
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	ladder := []sizer.ConfigEntry{
+		{Size: "xs", Diff: 10, Files: 1, Labels: []string{"size/xs"}},
+		{Size: "s", Diff: 50, Files: 10, Labels: []string{"size/s"}},
+	}
+
+	tests := []struct {
+		name    string
+		config  Config
+		wantErr bool
+	}{
+		{"Valid", Config{Config: sizer.Config{LabelConfigs: ladder, ExcludeFiles: []string{"vendor/**", "!vendor/ours/**"}}, FailAbove: "s"}, false},
+		{"EqualThresholds", Config{Config: sizer.Config{LabelConfigs: []sizer.ConfigEntry{ladder[0], {Size: "s", Diff: 10, Files: 1, Labels: []string{"size/s"}}}}}, false},
+		{"DefaultConfig", DefaultConfig(), false},
+		{"EmptyLadder", Config{}, true},
+		{"EmptySize", Config{Config: sizer.Config{LabelConfigs: []sizer.ConfigEntry{{Size: "", Diff: 10, Files: 1, Labels: []string{"size/xs"}}}}}, true},
+		{"DuplicateSize", Config{Config: sizer.Config{LabelConfigs: []sizer.ConfigEntry{ladder[0], ladder[0]}}}, true},
+		{"NoLabels", Config{Config: sizer.Config{LabelConfigs: []sizer.ConfigEntry{{Size: "xs", Diff: 10, Files: 1, Labels: nil}}}}, true},
+		{"EmptyLabel", Config{Config: sizer.Config{LabelConfigs: []sizer.ConfigEntry{{Size: "xs", Diff: 10, Files: 1, Labels: []string{" "}}}}}, true},
+		{"NegativeThreshold", Config{Config: sizer.Config{LabelConfigs: []sizer.ConfigEntry{{Size: "xs", Diff: -1, Files: 1, Labels: []string{"size/xs"}}}}}, true},
+		{"DecreasingDiff", Config{Config: sizer.Config{LabelConfigs: []sizer.ConfigEntry{ladder[1], {Size: "m", Diff: 20, Files: 20, Labels: []string{"size/m"}}}}}, true},
+		{"DecreasingFiles", Config{Config: sizer.Config{LabelConfigs: []sizer.ConfigEntry{ladder[1], {Size: "m", Diff: 100, Files: 5, Labels: []string{"size/m"}}}}}, true},
+		{"InvalidExcludePattern", Config{Config: sizer.Config{LabelConfigs: ladder, ExcludeFiles: []string{"[abc"}}}, true},
+		{"InvalidWeightPattern", Config{Config: sizer.Config{LabelConfigs: ladder, Weights: sizer.Weights{{Pattern: "[abc", Weight: 1}}}}, true},
+		{"NegativeWeight", Config{Config: sizer.Config{LabelConfigs: ladder, Weights: sizer.Weights{{Pattern: "docs/**", Weight: -1}}}}, true},
+		{"ValidColor", Config{Config: sizer.Config{LabelConfigs: []sizer.ConfigEntry{{Size: "xs", Diff: 10, Files: 1, Labels: []string{"size/xs"}, Color: "#3CBF00"}}}}, false},
+		{"InvalidColor", Config{Config: sizer.Config{LabelConfigs: []sizer.ConfigEntry{{Size: "xs", Diff: 10, Files: 1, Labels: []string{"size/xs"}, Color: "green"}}}}, true},
+		{"UnknownFailAbove", Config{Config: sizer.Config{LabelConfigs: ladder}, FailAbove: "xl"}, true},
+		{"MatchAll", Config{Config: sizer.Config{LabelConfigs: []sizer.ConfigEntry{{Size: "xs", Diff: 10, Files: 1, Commits: new(3), Match: sizer.MatchAll, Labels: []string{"size/xs"}}}}}, false},
+		{"ValidWhen", Config{Config: sizer.Config{LabelConfigs: []sizer.ConfigEntry{{Size: "xs", Diff: 10, Files: 1, When: `any(paths, glob(#, "db/migrations/**"))`, Labels: []string{"size/xs"}}}}}, false},
+		{"InvalidWhen", Config{Config: sizer.Config{LabelConfigs: []sizer.ConfigEntry{{Size: "xs", Diff: 10, Files: 1, When: "lines >", Labels: []string{"size/xs"}}}}}, true},
+		{"ValidPathLabels", Config{Config: sizer.Config{LabelConfigs: ladder}, PathLabels: []PathLabel{{Label: "area/frontend", Paths: []string{"web/**"}, MinLines: 10}}}, false},
+		{"PathLabelWithoutPaths", Config{Config: sizer.Config{LabelConfigs: ladder}, PathLabels: []PathLabel{{Label: "area/frontend"}}}, true},
+		{"PathLabelInvalidPattern", Config{Config: sizer.Config{LabelConfigs: ladder}, PathLabels: []PathLabel{{Label: "area/frontend", Paths: []string{"[abc"}}}}, true},
+		{"PathLabelIsSizeLabel", Config{Config: sizer.Config{LabelConfigs: ladder}, PathLabels: []PathLabel{{Label: "size/s", Paths: []string{"web/**"}}}}, true},
+		{"PathLabelNegativeMinLines", Config{Config: sizer.Config{LabelConfigs: ladder}, PathLabels: []PathLabel{{Label: "area/frontend", Paths: []string{"web/**"}, MinLines: -1}}}, true},
+		{"InvalidMatch", Config{Config: sizer.Config{LabelConfigs: []sizer.ConfigEntry{{Size: "xs", Diff: 10, Files: 1, Match: "both", Labels: []string{"size/xs"}}}}}, true},
+		{"NegativeCommits", Config{Config: sizer.Config{LabelConfigs: []sizer.ConfigEntry{{Size: "xs", Diff: 10, Files: 1, Commits: new(-1), Labels: []string{"size/xs"}}}}}, true},
+		{"DecreasingRenames", Config{Config: sizer.Config{LabelConfigs: []sizer.ConfigEntry{{Size: "xs", Diff: 10, Files: 1, Renames: new(5), Labels: []string{"size/xs"}}, {Size: "s", Diff: 50, Files: 10, Renames: new(2), Labels: []string{"size/s"}}}}}, true},
+		{"DirectoriesOnSmallerSizeOnly", Config{Config: sizer.Config{LabelConfigs: []sizer.ConfigEntry{{Size: "xs", Diff: 10, Files: 1, Directories: new(2), Labels: []string{"size/xs"}}, ladder[1]}}}, false},
+		{"ValidScopes", Config{Config: sizer.Config{LabelConfigs: ladder, Scopes: []sizer.Scope{{Name: "api", Path: "services/api"}, {Name: "web", Path: "web", LabelConfigs: []sizer.ConfigEntry{{Size: "s", Diff: 20, Files: 2}}}}}}, false},
+		{"ScopeWithoutName", Config{Config: sizer.Config{LabelConfigs: ladder, Scopes: []sizer.Scope{{Path: "services/api"}}}}, true},
+		{"ScopeWithoutPath", Config{Config: sizer.Config{LabelConfigs: ladder, Scopes: []sizer.Scope{{Name: "api"}}}}, true},
+		{"DuplicateScopeName", Config{Config: sizer.Config{LabelConfigs: ladder, Scopes: []sizer.Scope{{Name: "api", Path: "api"}, {Name: "api", Path: "services/api"}}}}, true},
+		{"ScopeDecreasingDiff", Config{Config: sizer.Config{LabelConfigs: ladder, Scopes: []sizer.Scope{{Name: "api", Path: "api", LabelConfigs: []sizer.ConfigEntry{{Size: "s", Diff: 20, Files: 2}, {Size: "m", Diff: 10, Files: 5}}}}}}, true},
+		{"ScopeLabelIsSizeLabel", Config{Config: sizer.Config{LabelConfigs: ladder, Scopes: []sizer.Scope{{Name: "api", Path: "api", LabelConfigs: []sizer.ConfigEntry{{Size: "s", Diff: 20, Files: 2, Labels: []string{"size/s"}}}}}}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.config.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfigErrorError(t *testing.T) {
+	errs := ConfigErrors{
+		{Line: 3, Err: errors.New("first problem")},
+		{Err: ErrNoLabelConfigs},
+	}
+
+	want := "line 3: first problem\nlabel_configs must define at least one size"
+	if got := errs.Error(); got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+	if !errors.Is(errs, ErrNoLabelConfigs) {
+		t.Errorf("errors.Is() = false, want true")
+	}
+}
+
+func TestParseConfigMissingThresholds(t *testing.T) {
+	data := []byte(`label_configs:
+  - size: s
+    commits: 3
+    labels: ["size/s"]
+  - size: m
+    diff: 500
+    labels: ["size/m"]
+`)
+
+	_, err := parseConfig(data, nil)
+	var configErrs ConfigErrors
+	if !errors.As(err, &configErrs) {
+		t.Fatalf("parseConfig() error = %v, want ConfigErrors", err)
+	}
+
+	var got []string
+	for _, configErr := range configErrs {
+		got = append(got, configErr.Error())
+	}
+	want := []string{
+		"line 2: label_configs[0]: files must be set, as an unset threshold of 0 only fits pull requests without changes",
+		"line 2: label_configs[0]: diff must be set, as an unset threshold of 0 only fits pull requests without changes",
+		"line 5: label_configs[1]: files must be set, as an unset threshold of 0 only fits pull requests without changes",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseConfig() errors = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"errors"
+	"net"
+	"net/http"
+
+	"github.com/google/go-github/v90/github"
+)
+
+// Exit codes of the action, so workflows can tell the kinds of failures apart.
+const (
+	ExitCodeSuccess  = 0
+	ExitCodeFailure  = 1 // ExitCodeFailure is used for errors without a specific kind.
+	ExitCodeConfig   = 2
+	ExitCodeAuth     = 3
+	ExitCodeAPI      = 4
+	ExitCodeNotFound = 5
+)
+
+// Kinds of errors, each mapped to its own exit code.
+var (
+	ErrConfig   = errors.New("configuration error")
+	ErrAuth     = errors.New("authentication error")
+	ErrAPI      = errors.New("GitHub API error")
+	ErrNotFound = errors.New("not found")
+)
+
+// kindError attaches a kind to an error without changing its message.
+type kindError struct {
+	kind error
+	err  error
+}
+
+// Error returns the message of the wrapped error.
+func (e *kindError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the kind and the wrapped error, so errors.Is matches both.
+func (e *kindError) Unwrap() []error {
+	return []error{e.kind, e.err}
+}
+
+// withKind attaches a kind, such as ErrConfig, to an error. It returns nil for a nil error.
+func withKind(kind, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &kindError{kind: kind, err: err}
+}
+
+// errorKind returns the kind of an error. Errors returned by the GitHub API are classified by their status code.
+// It returns nil if the error has no specific kind.
+func errorKind(err error) error {
+	for _, kind := range []error{ErrConfig, ErrAuth, ErrNotFound, ErrAPI} {
+		if errors.Is(err, kind) {
+			return kind
+		}
+	}
+
+	var configErr ConfigError
+	if errors.As(err, &configErr) || errors.Is(err, ErrNoLabelConfigs) {
+		return ErrConfig
+	}
+
+	var errResp *github.ErrorResponse
+	if errors.As(err, &errResp) && errResp.Response != nil {
+		switch errResp.Response.StatusCode {
+		case http.StatusUnauthorized, http.StatusForbidden:
+			return ErrAuth
+		case http.StatusNotFound:
+			return ErrNotFound
+		}
+		return ErrAPI
+	}
+
+	var rateLimitErr *github.RateLimitError
+	var abuseErr *github.AbuseRateLimitError
+	var netErr net.Error
+	if errors.As(err, &rateLimitErr) || errors.As(err, &abuseErr) || errors.As(err, &netErr) {
+		return ErrAPI
+	}
+	return nil
+}
+
+// exitCode returns the exit code for an error.
+func exitCode(err error) int {
+	if err == nil {
+		return ExitCodeSuccess
+	}
+
+	switch errorKind(err) {
+	case ErrConfig:
+		return ExitCodeConfig
+	case ErrAuth:
+		return ExitCodeAuth
+	case ErrAPI:
+		return ExitCodeAPI
+	case ErrNotFound:
+		return ExitCodeNotFound
+	}
+	return ExitCodeFailure
+}
+
+// exitStatus logs the error and returns the exit code to terminate with.
+// If failOnError is false, the error is reported as a warning annotation and the action succeeds.
+func exitStatus(err error, failOnError bool) int {
+	if err == nil {
+		return ExitCodeSuccess
+	}
+
+	if !failOnError {
+		logger.Warningf("%v", err)
+		return ExitCodeSuccess
+	}
+
+	logger.Errorf("%v", err)
+	return exitCode(err)
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-github/v90/github"
+)
+
+// newErrorResponse creates an error as returned by the GitHub client for a failed request.
+func newErrorResponse(statusCode int) error {
+	return &github.ErrorResponse{Response: &http.Response{StatusCode: statusCode}, Message: http.StatusText(statusCode)}
+}
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"NoError", nil, ExitCodeSuccess},
+		{"UnknownError", errors.New("something failed"), ExitCodeFailure},
+		{"ConfigErrors", fmt.Errorf("loading configuration: %w", ConfigErrors{{Line: 2, Err: ErrNoLabelConfigs}}), ExitCodeConfig},
+		{"ConfigKind", withKind(ErrConfig, errors.New("permission denied")), ExitCodeConfig},
+		{"BadCredentials", fmt.Errorf("fetching pull request: %w", newErrorResponse(http.StatusUnauthorized)), ExitCodeAuth},
+		{"MissingPermission", newErrorResponse(http.StatusForbidden), ExitCodeAuth},
+		{"PullRequestNotFound", fmt.Errorf("fetching pull request: %w", newErrorResponse(http.StatusNotFound)), ExitCodeNotFound},
+		{"ServerError", newErrorResponse(http.StatusBadGateway), ExitCodeAPI},
+		{"RateLimit", &github.RateLimitError{Message: "API rate limit exceeded"}, ExitCodeAPI},
+		{"SecondaryRateLimit", &github.AbuseRateLimitError{Message: "secondary rate limit"}, ExitCodeAPI},
+		{"JoinedErrors", errors.Join(errors.New("something failed"), newErrorResponse(http.StatusNotFound)), ExitCodeNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exitCode(tt.err); got != tt.want {
+				t.Errorf("exitCode(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExitStatus(t *testing.T) {
+	err := newErrorResponse(http.StatusNotFound)
+
+	if got := exitStatus(nil, true); got != ExitCodeSuccess {
+		t.Errorf("exitStatus(nil) = %v, want %v", got, ExitCodeSuccess)
+	}
+	if got := exitStatus(err, true); got != ExitCodeNotFound {
+		t.Errorf("exitStatus() = %v, want %v", got, ExitCodeNotFound)
+	}
+	if got := exitStatus(err, false); got != ExitCodeSuccess {
+		t.Errorf("exitStatus() without fail on error = %v, want %v", got, ExitCodeSuccess)
+	}
+}
+
+func TestWithKind(t *testing.T) {
+	err := errors.New("permission denied")
+	got := withKind(ErrConfig, err)
+
+	if got.Error() != err.Error() || !errors.Is(got, ErrConfig) || !errors.Is(got, err) {
+		t.Errorf("withKind() = %v, want the message of the error matching ErrConfig and the error", got)
+	}
+	if withKind(ErrConfig, nil) != nil {
+		t.Errorf("withKind(nil) is not nil")
+	}
+}
@@ -0,0 +1,345 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v90/github"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// MaxExtendsDepth limits how many shared configurations can extend each other.
+	MaxExtendsDepth = 10
+	// MaxSharedConfigSize limits the size of a shared configuration fetched from a URL.
+	MaxSharedConfigSize = 1 << 20
+	// SharedConfigTimeout limits the time to fetch a shared configuration from a URL.
+	SharedConfigTimeout = 30 * time.Second
+)
+
+// ConfigRef references a shared configuration, either a file of a repository or an HTTPS URL.
+type ConfigRef struct {
+	Owner string
+	Repo  string
+	Path  string
+	Ref   string // Ref is the branch, tag or commit of the file, or empty for the default branch.
+	URL   string
+}
+
+// ParseConfigRef parses a reference in the form owner/repo:path@ref, where @ref is optional, or an HTTPS URL.
+func ParseConfigRef(s string) (ConfigRef, error) {
+	if strings.HasPrefix(s, "https://") {
+		if _, err := url.Parse(s); err != nil {
+			return ConfigRef{}, fmt.Errorf("extends %q is not a valid URL: %w", s, err)
+		}
+		return ConfigRef{URL: s}, nil
+	}
+	if strings.HasPrefix(s, "http://") {
+		return ConfigRef{}, fmt.Errorf("extends %q must use https", s)
+	}
+
+	invalid := fmt.Errorf("extends %q must be owner/repo:path@ref or an https URL", s)
+	repoName, filePath, ok := strings.Cut(s, ":")
+	if !ok || !isValidRepoNameFormat(repoName) {
+		return ConfigRef{}, invalid
+	}
+
+	var ref string
+	if i := strings.LastIndex(filePath, "@"); i >= 0 {
+		filePath, ref = filePath[:i], filePath[i+1:]
+	}
+	if filePath == "" {
+		return ConfigRef{}, invalid
+	}
+	return ConfigRef{Owner: parseRepoOwner(repoName), Repo: parseRepoName(repoName), Path: filePath, Ref: ref}, nil
+}
+
+// String returns the reference in the form it is parsed from.
+func (r ConfigRef) String() string {
+	if r.URL != "" {
+		return r.URL
+	}
+	s := r.Owner + "/" + r.Repo + ":" + r.Path
+	if r.Ref != "" {
+		s += "@" + r.Ref
+	}
+	return s
+}
+
+// ConfigFetcher fetches the shared configurations referenced by extends.
+type ConfigFetcher interface {
+	FetchConfig(ref ConfigRef) ([]byte, error)
+}
+
+// remoteConfigFetcher fetches shared configurations through the GitHub API and from URLs.
+type remoteConfigFetcher struct {
+	ctx        context.Context
+	client     *github.Client
+	httpClient *http.Client
+}
+
+// newConfigFetcher creates a fetcher reading files of repositories with the given client.
+// URLs are fetched without credentials, so the token is never sent to other hosts.
+func newConfigFetcher(ctx context.Context, client *github.Client) ConfigFetcher {
+	return remoteConfigFetcher{
+		ctx:        ctx,
+		client:     client,
+		httpClient: &http.Client{Timeout: SharedConfigTimeout, Transport: newRetryTransport(nil)},
+	}
+}
+
+// newLocalConfigFetcher creates the fetcher of the subcommands, which only use GitHub to fetch shared configurations.
+// Without a token or GitHub App credentials, files of public repositories are fetched anonymously.
+func newLocalConfigFetcher(args EnvArgs) (ConfigFetcher, error) {
+	ctx := context.Background()
+	if args.GithubToken == "" && !args.usesGitHubApp() {
+		client, err := newGitHubClient(ctx, nil, args.GitHubEnterpriseUrl)
+		if err != nil {
+			return nil, err
+		}
+		return newConfigFetcher(ctx, client), nil
+	}
+
+	clientWrapper, err := newGitHubClientWrapperFromArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	return newConfigFetcher(ctx, clientWrapper.client), nil
+}
+
+// FetchConfig fetches a shared configuration. A missing file is reported as a configuration error.
+func (f remoteConfigFetcher) FetchConfig(ref ConfigRef) ([]byte, error) {
+	if ref.URL != "" {
+		return f.fetchURL(ref.URL)
+	}
+
+	data, found, err := fetchRemoteConfig(f.ctx, f.client, ref.Owner, ref.Repo, ref.Path, ref.Ref)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, withKind(ErrConfig, fmt.Errorf("%s not found", ref))
+	}
+	return data, nil
+}
+
+// fetchURL fetches a shared configuration from a URL.
+func (f remoteConfigFetcher) fetchURL(rawURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(f.ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, withKind(ErrConfig, err)
+	}
+	resp, err := f.httpClient.Do(req)
+	if err != nil {
+		return nil, withKind(ErrAPI, err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, withKind(ErrConfig, fmt.Errorf("%s not found", rawURL))
+	case resp.StatusCode != http.StatusOK:
+		return nil, withKind(ErrAPI, fmt.Errorf("fetching %s: %s", rawURL, resp.Status))
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, MaxSharedConfigSize+1))
+	if err != nil {
+		return nil, withKind(ErrAPI, err)
+	}
+	if len(data) > MaxSharedConfigSize {
+		return nil, withKind(ErrConfig, fmt.Errorf("%s is larger than %d bytes", rawURL, MaxSharedConfigSize))
+	}
+	return data, nil
+}
+
+// resolveExtends fetches the shared configuration referenced by extends, merged with the configurations it extends itself.
+// chain holds the references extending it, to detect loops. Problems of the configurations are returned as ErrConfig.
+func resolveExtends(fetcher ConfigFetcher, extends string, chain []string) (*yaml.Node, error) {
+	ref, err := ParseConfigRef(extends)
+	if err != nil {
+		return nil, withKind(ErrConfig, err)
+	}
+	chain = append(slices.Clone(chain), ref.String())
+	if slices.Contains(chain[:len(chain)-1], ref.String()) {
+		return nil, withKind(ErrConfig, fmt.Errorf("extends loop: %s", strings.Join(chain, " -> ")))
+	}
+	if len(chain) > MaxExtendsDepth {
+		return nil, withKind(ErrConfig, fmt.Errorf("extends chain is longer than %d: %s", MaxExtendsDepth, strings.Join(chain, " -> ")))
+	}
+
+	data, err := fetcher.FetchConfig(ref)
+	if err != nil {
+		return nil, fmt.Errorf("fetching %s: %w", ref, err)
+	}
+	config, root, errs, err := decodeConfig(data)
+	if err != nil {
+		return nil, withKind(ErrConfig, fmt.Errorf("%s: %w", ref, err))
+	}
+	if len(errs) > 0 {
+		return nil, withKind(ErrConfig, fmt.Errorf("%s:\n%w", ref, errs))
+	}
+
+	if config.Extends == "" {
+		return root, nil
+	}
+	base, err := resolveExtends(fetcher, config.Extends, chain)
+	if err != nil {
+		return nil, err
+	}
+	return mergeConfigNodes(base, root), nil
+}
+
+// sequenceMergeKeys names the field entries of sequences are merged by, for each key holding such a sequence.
+var sequenceMergeKeys = map[string]string{
+	"label_configs": "size",
+	"scopes":        "name",
+	"path_labels":   "label",
+}
+
+// mergeConfigNodes deep-merges a configuration into the shared configuration it extends, both YAML documents.
+// Mappings are merged key by key, keeping the order of the shared configuration and adding new keys at the end,
+// except for weights, where new patterns come first.
+// Entries of label_configs, scopes and path_labels are merged by their size, name and label the same way.
+// The patterns of exclude_files are concatenated, see concatSequences. For everything else, the local value wins.
+// The nodes taken from the shared configuration have no line numbers, as they are not part of the local file.
+func mergeConfigNodes(base, local *yaml.Node) *yaml.Node {
+	if len(base.Content) == 0 || len(local.Content) == 0 {
+		return local
+	}
+
+	merged := *local
+	merged.Content = []*yaml.Node{mergeNodes(withoutLines(base.Content[0]), local.Content[0], "")}
+	return &merged
+}
+
+// mergeNodes merges the local node into the shared node, where key is the mapping key holding both.
+func mergeNodes(base, local *yaml.Node, key string) *yaml.Node {
+	switch {
+	case base.Kind == yaml.MappingNode && local.Kind == yaml.MappingNode && key == "weights":
+		return mergeWeights(base, local)
+	case base.Kind == yaml.MappingNode && local.Kind == yaml.MappingNode:
+		return mergeMappings(base, local)
+	case base.Kind == yaml.SequenceNode && local.Kind == yaml.SequenceNode && key == "exclude_files":
+		return concatSequences(base, local)
+	case base.Kind == yaml.SequenceNode && local.Kind == yaml.SequenceNode && sequenceMergeKeys[key] != "":
+		return mergeSequences(base, local, sequenceMergeKeys[key])
+	}
+	return local
+}
+
+// mergeMappings merges two mapping nodes key by key.
+func mergeMappings(base, local *yaml.Node) *yaml.Node {
+	merged := *local
+	merged.Content = nil
+	for i := 0; i+1 < len(base.Content); i += 2 {
+		key, value := base.Content[i], base.Content[i+1]
+		if localValue := childNode(local, key.Value); localValue != nil {
+			merged.Content = append(merged.Content, mappingKey(local, key.Value), mergeNodes(value, localValue, key.Value))
+		} else {
+			merged.Content = append(merged.Content, key, value)
+		}
+	}
+	for i := 0; i+1 < len(local.Content); i += 2 {
+		if childNode(base, local.Content[i].Value) == nil {
+			merged.Content = append(merged.Content, local.Content[i], local.Content[i+1])
+		}
+	}
+	return &merged
+}
+
+// mergeWeights merges two weights mappings. As the first matching pattern wins, the patterns only the local
+// configuration has come first, so they can override the weight of narrower paths. The shared patterns follow,
+// with the weights the local configuration sets for them.
+func mergeWeights(base, local *yaml.Node) *yaml.Node {
+	merged := mergeMappings(base, local)
+	shared := len(base.Content)
+	merged.Content = append(slices.Clone(merged.Content[shared:]), merged.Content[:shared]...)
+	return merged
+}
+
+// mergeSequences merges two sequences of mappings, merging the entries with the same value of the given field.
+func mergeSequences(base, local *yaml.Node, field string) *yaml.Node {
+	merged := *local
+	merged.Content = nil
+	used := make(map[*yaml.Node]bool)
+	for _, entry := range base.Content {
+		localEntry := findEntry(local, field, entry)
+		if localEntry == nil {
+			merged.Content = append(merged.Content, entry)
+			continue
+		}
+		merged.Content = append(merged.Content, mergeNodes(entry, localEntry, ""))
+		used[localEntry] = true
+	}
+	for _, entry := range local.Content {
+		if !used[entry] {
+			merged.Content = append(merged.Content, entry)
+		}
+	}
+	return &merged
+}
+
+// findEntry returns the entry of the sequence with the same value of the given field as the entry, or nil.
+func findEntry(sequence *yaml.Node, field string, entry *yaml.Node) *yaml.Node {
+	value := childNode(entry, field)
+	if value == nil {
+		return nil
+	}
+	for _, candidate := range sequence.Content {
+		if candidateValue := childNode(candidate, field); candidateValue != nil && candidateValue.Value == value.Value {
+			return candidate
+		}
+	}
+	return nil
+}
+
+// concatSequences appends the local patterns to the shared ones. A pattern that is already there is skipped,
+// unless a negated pattern comes after it, as repeating the pattern matches the files the negation unmatched again.
+func concatSequences(base, local *yaml.Node) *yaml.Node {
+	merged := *local
+	merged.Content = slices.Clone(base.Content)
+	for _, entry := range local.Content {
+		last := -1
+		for i, existing := range merged.Content {
+			if existing.Value == entry.Value {
+				last = i
+			}
+		}
+		if last < 0 || slices.ContainsFunc(merged.Content[last+1:], isNegatedPattern) {
+			merged.Content = append(merged.Content, entry)
+		}
+	}
+	return &merged
+}
+
+// isNegatedPattern checks if a pattern node unmatches files matched by earlier patterns.
+func isNegatedPattern(node *yaml.Node) bool {
+	return strings.HasPrefix(node.Value, "!")
+}
+
+// mappingKey returns the key node of a mapping with the given value.
+func mappingKey(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i]
+		}
+	}
+	return nil
+}
+
+// withoutLines returns a deep copy of the node without line and column numbers.
+func withoutLines(node *yaml.Node) *yaml.Node {
+	copied := *node
+	copied.Line, copied.Column = 0, 0
+	copied.Content = make([]*yaml.Node, 0, len(node.Content))
+	for _, child := range node.Content {
+		copied.Content = append(copied.Content, withoutLines(child))
+	}
+	return &copied
+}
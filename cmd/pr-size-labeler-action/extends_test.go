@@ -0,0 +1,261 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/cbrgm/pr-size-labeler-action/pkg/sizer"
+	"github.com/google/go-github/v90/github"
+)
+
+// mapConfigFetcher serves shared configurations from a map keyed by their reference.
+type mapConfigFetcher map[string]string
+
+func (f mapConfigFetcher) FetchConfig(ref ConfigRef) ([]byte, error) {
+	data, ok := f[ref.String()]
+	if !ok {
+		return nil, withKind(ErrConfig, errors.New(ref.String()+" not found"))
+	}
+	return []byte(data), nil
+}
+
+const testSharedConfig = `exclude_files: ["go.sum", "vendor/**"]
+label_configs:
+  - size: s
+    diff: 100
+    files: 10
+    labels: ["size/s"]
+  - size: m
+    diff: 500
+    files: 50
+    labels: ["size/m"]
+sticky_comment: true
+`
+
+func TestParseConfigRef(t *testing.T) {
+	tests := []struct {
+		name    string
+		extends string
+		want    ConfigRef
+		wantErr bool
+	}{
+		{"RepositoryFile", "acme/shared:pr-size.yml@v1", ConfigRef{Owner: "acme", Repo: "shared", Path: "pr-size.yml", Ref: "v1"}, false},
+		{"DefaultBranch", "acme/shared:configs/pr-size.yml", ConfigRef{Owner: "acme", Repo: "shared", Path: "configs/pr-size.yml"}, false},
+		{"URL", "https://example.com/pr-size.yml", ConfigRef{URL: "https://example.com/pr-size.yml"}, false},
+		{"PlainHTTP", "http://example.com/pr-size.yml", ConfigRef{}, true},
+		{"MissingPath", "acme/shared:@v1", ConfigRef{}, true},
+		{"MissingRepository", "pr-size.yml", ConfigRef{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseConfigRef(tt.extends)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("ParseConfigRef() = %+v, %v, want %+v, wantErr %v", got, err, tt.want, tt.wantErr)
+			}
+			if err == nil && got.String() != tt.extends {
+				t.Errorf("String() = %q, want %q", got.String(), tt.extends)
+			}
+		})
+	}
+}
+
+func TestParseConfigExtends(t *testing.T) {
+	fetcher := mapConfigFetcher{"acme/shared:pr-size.yml@v1": testSharedConfig}
+	data := []byte(`extends: acme/shared:pr-size.yml@v1
+exclude_files: ["docs/**", "go.sum"]
+label_configs:
+  - size: m
+    diff: 800
+  - size: l
+    diff: 2000
+    files: 100
+    labels: ["size/l"]
+`)
+
+	config, err := parseConfig(data, fetcher)
+	if err != nil {
+		t.Fatalf("parseConfig() error = %v", err)
+	}
+	if want := []string{"go.sum", "vendor/**", "docs/**"}; !reflect.DeepEqual(config.ExcludeFiles, want) {
+		t.Errorf("parseConfig() exclude_files = %v, want %v", config.ExcludeFiles, want)
+	}
+
+	var got []string
+	for _, entry := range config.LabelConfigs {
+		got = append(got, entry.Size+":"+strings.Join(entry.Labels, ","))
+	}
+	if want := []string{"s:size/s", "m:size/m", "l:size/l"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("parseConfig() sizes = %v, want %v", got, want)
+	}
+	if m := config.LabelConfigs[1]; m.Diff != 800 || m.Files != 50 {
+		t.Errorf("parseConfig() size m = %+v, want diff 800 from the local file and files 50 from the shared one", m)
+	}
+	if !config.StickyComment {
+		t.Errorf("parseConfig() sticky_comment = false, want it inherited from the shared configuration")
+	}
+}
+
+func TestParseConfigExtendsErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		fetcher  mapConfigFetcher
+		data     string
+		wantLine int
+		wantMsg  string
+	}{
+		{
+			"Loop",
+			mapConfigFetcher{
+				"acme/a:pr-size.yml": "extends: acme/b:pr-size.yml\n",
+				"acme/b:pr-size.yml": "extends: acme/a:pr-size.yml\n",
+			},
+			"sticky_comment: true\nextends: acme/a:pr-size.yml\n",
+			2,
+			"extends loop: acme/a:pr-size.yml -> acme/b:pr-size.yml -> acme/a:pr-size.yml",
+		},
+		{
+			"NotFound",
+			mapConfigFetcher{},
+			"extends: acme/shared:pr-size.yml\n",
+			1,
+			"not found",
+		},
+		{
+			"InvalidSharedConfig",
+			mapConfigFetcher{"acme/shared:pr-size.yml": "lable_configs: []\n"},
+			"extends: acme/shared:pr-size.yml\n",
+			1,
+			"field lable_configs not found",
+		},
+		{
+			"MergedThresholdsDecrease",
+			mapConfigFetcher{"acme/shared:pr-size.yml": testSharedConfig},
+			"extends: acme/shared:pr-size.yml\nlabel_configs:\n  - size: s\n    diff: 1000\n",
+			3,
+			"diff 500 must not be lower than diff 1000",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseConfig([]byte(tt.data), tt.fetcher)
+			var configErrs ConfigErrors
+			if !errors.As(err, &configErrs) {
+				t.Fatalf("parseConfig() error = %v, want ConfigErrors", err)
+			}
+			if configErrs[0].Line != tt.wantLine || !strings.Contains(configErrs[0].Error(), tt.wantMsg) {
+				t.Errorf("parseConfig() error = %v, want %q on line %d", configErrs[0], tt.wantMsg, tt.wantLine)
+			}
+		})
+	}
+}
+
+func TestRemoteConfigFetcher(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/acme/shared/contents/pr-size.yml":
+			if r.URL.Query().Get("ref") != "v1" {
+				t.Errorf("unexpected ref %q", r.URL.Query().Get("ref"))
+			}
+			_ = json.NewEncoder(w).Encode(map[string]string{
+				"type":     "file",
+				"encoding": "base64",
+				"content":  base64.StdEncoding.EncodeToString([]byte(testSharedConfig)),
+			})
+		case "/raw/pr-size.yml":
+			_, _ = w.Write([]byte(testSharedConfig))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	serverURL := server.URL + "/"
+	client, err := github.NewClient(github.WithHTTPClient(server.Client()), github.WithURLs(&serverURL, &serverURL))
+	if err != nil {
+		t.Fatalf("creating client: %v", err)
+	}
+	fetcher := remoteConfigFetcher{ctx: t.Context(), client: client, httpClient: server.Client()}
+
+	tests := []struct {
+		name    string
+		extends string
+		wantErr error
+	}{
+		{"RepositoryFile", "acme/shared:pr-size.yml@v1", nil},
+		{"URL", server.URL + "/raw/pr-size.yml", nil},
+		{"MissingRepositoryFile", "acme/shared:missing.yml", ErrConfig},
+		{"MissingURL", server.URL + "/raw/missing.yml", ErrConfig},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ref, err := ParseConfigRef(tt.extends)
+			if err != nil {
+				t.Fatalf("ParseConfigRef() error = %v", err)
+			}
+			data, err := fetcher.FetchConfig(ref)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("FetchConfig() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && string(data) != testSharedConfig {
+				t.Errorf("FetchConfig() = %q, want the shared configuration", data)
+			}
+		})
+	}
+}
+
+func TestParseConfigExtendsWeights(t *testing.T) {
+	fetcher := mapConfigFetcher{"acme/shared:pr-size.yml": testSharedConfig + `weights:
+  "docs/**": 0.25
+  "**/*_test.go": 0.5
+`}
+	data := []byte(`extends: acme/shared:pr-size.yml
+weights:
+  "docs/api/**": 1.0
+  "**/*_test.go": 0.75
+`)
+
+	config, err := parseConfig(data, fetcher)
+	if err != nil {
+		t.Fatalf("parseConfig() error = %v", err)
+	}
+
+	tests := []struct {
+		filename string
+		want     float64
+	}{
+		{"docs/api/openapi.md", 1.0},
+		{"docs/guide.md", 0.25},
+		{"main_test.go", 0.75},
+	}
+	for _, tt := range tests {
+		if got := config.Weights.WeightFor(tt.filename); got != tt.want {
+			t.Errorf("WeightFor(%q) = %v, want %v", tt.filename, got, tt.want)
+		}
+	}
+}
+
+func TestParseConfigExtendsNegatedExcludeFiles(t *testing.T) {
+	shared := strings.Replace(testSharedConfig, `exclude_files: ["go.sum", "vendor/**"]`, `exclude_files: ["vendor/**", "!vendor/ours/**"]`, 1)
+	fetcher := mapConfigFetcher{"acme/shared:pr-size.yml": shared}
+	data := []byte("extends: acme/shared:pr-size.yml\nexclude_files: [\"vendor/**\"]\n")
+
+	config, err := parseConfig(data, fetcher)
+	if err != nil {
+		t.Fatalf("parseConfig() error = %v", err)
+	}
+	if want := []string{"vendor/**", "!vendor/ours/**", "vendor/**"}; !reflect.DeepEqual(config.ExcludeFiles, want) {
+		t.Errorf("parseConfig() exclude_files = %v, want %v", config.ExcludeFiles, want)
+	}
+	if !sizer.IsExcluded("vendor/ours/a.go", config.Config, nil) {
+		t.Errorf("IsExcluded(vendor/ours/a.go) = false, want the local pattern to exclude it again")
+	}
+}
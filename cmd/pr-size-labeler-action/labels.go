@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/cbrgm/pr-size-labeler-action/pkg/sizer"
+	"github.com/google/go-github/v90/github"
+)
+
+// LabelsPerPage is the page size used when listing the labels of the repository.
+const LabelsPerPage = 100
+
+// labelColorPattern matches a hex color, such as "0e8a16" or "#0e8a16".
+var labelColorPattern = regexp.MustCompile(`^#?[0-9a-fA-F]{6}$`)
+
+// LabelSyncer creates the styled labels of a configuration in the repository and keeps their color and description up to date.
+// It runs once per run, before any pull request is processed, so concurrent pull requests do not race to create labels.
+type LabelSyncer struct {
+	clientWrapper *GitHubClientWrapper
+	repoOwner     string
+	repoName      string
+	config        Config
+	dryRun        bool
+	ctx           context.Context
+}
+
+// NewLabelSyncer creates a new LabelSyncer instance.
+func NewLabelSyncer(ctx context.Context, clientWrapper *GitHubClientWrapper, repoOwner, repoName string, config Config, dryRun bool) *LabelSyncer {
+	return &LabelSyncer{
+		clientWrapper: clientWrapper,
+		repoOwner:     repoOwner,
+		repoName:      repoName,
+		config:        config,
+		dryRun:        dryRun,
+		ctx:           ctx,
+	}
+}
+
+// SyncLabels creates the labels of all sizes in the repository or updates their color and description.
+// Labels without a color and description are left alone, so GitHub creates them with its defaults when they are added.
+func (ls *LabelSyncer) SyncLabels() error {
+	names := styledLabels(ls.config.allLabelConfigs())
+	if len(names) == 0 {
+		return nil
+	}
+
+	existing, err := ls.listLabels()
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		style := labelStyle(ls.config.allLabelConfigs(), name)
+		label, found := existing[strings.ToLower(name)]
+		switch {
+		case !found:
+			err = ls.createLabel(name, style)
+		case labelNeedsUpdate(label, style):
+			err = ls.updateLabel(name, style)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// styledLabels returns the labels with a color or description, each once.
+func styledLabels(labelConfigs []sizer.ConfigEntry) []string {
+	var names []string
+	for _, entry := range labelConfigs {
+		for _, name := range entry.Labels {
+			if style := labelStyle(labelConfigs, name); style.Color != "" || style.Description != "" {
+				names = appendMissing(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// listLabels returns the labels of the repository by their name in lower case, as GitHub compares label names without case.
+func (ls *LabelSyncer) listLabels() (map[string]*github.Label, error) {
+	labels := make(map[string]*github.Label)
+	opts := &github.ListOptions{PerPage: LabelsPerPage}
+	for {
+		page, resp, err := ls.clientWrapper.client.Issues.ListLabels(ls.ctx, ls.repoOwner, ls.repoName, opts)
+		if err != nil {
+			return nil, fmt.Errorf("listing labels: %w", err)
+		}
+		for _, label := range page {
+			labels[strings.ToLower(label.GetName())] = label
+		}
+		if resp.NextPage == 0 {
+			return labels, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// labelStyle returns the first entry defining a color or description for the label.
+// A label shared by several sizes, such as "pairing-wanted", keeps the same color for all of them.
+func labelStyle(labelConfigs []sizer.ConfigEntry, name string) sizer.ConfigEntry {
+	for _, entry := range labelConfigs {
+		if contains(entry.Labels, name) && (entry.Color != "" || entry.Description != "") {
+			return entry
+		}
+	}
+	return sizer.ConfigEntry{}
+}
+
+// createLabel creates a label with the color and description of the entry in the repository.
+// A label created by another run in the meantime is not an error.
+func (ls *LabelSyncer) createLabel(name string, entry sizer.ConfigEntry) error {
+	if ls.dryRun {
+		logger.Infof("Dry run: would create label %q", name)
+		return nil
+	}
+
+	color, description := labelColorAndDescription(entry)
+	_, _, err := ls.clientWrapper.client.Issues.CreateLabel(ls.ctx, ls.repoOwner, ls.repoName, github.CreateIssueLabelRequest{
+		Name:        name,
+		Color:       color,
+		Description: description,
+	})
+	if err != nil && !isAlreadyExists(err) {
+		return fmt.Errorf("creating label %q: %w", name, err)
+	}
+	return nil
+}
+
+// updateLabel updates the color and description of a label in the repository.
+// Label names are escaped because the API expects them as a single path segment, for example "size%2Fxs".
+func (ls *LabelSyncer) updateLabel(name string, entry sizer.ConfigEntry) error {
+	if ls.dryRun {
+		logger.Infof("Dry run: would update label %q", name)
+		return nil
+	}
+
+	color, description := labelColorAndDescription(entry)
+	_, _, err := ls.clientWrapper.client.Issues.UpdateLabel(ls.ctx, ls.repoOwner, ls.repoName, url.PathEscape(name), github.UpdateIssueLabelRequest{
+		Color:       color,
+		Description: description,
+	})
+	if err != nil {
+		return fmt.Errorf("updating label %q: %w", name, err)
+	}
+	return nil
+}
+
+// isAlreadyExists checks if a request failed because the resource already exists.
+func isAlreadyExists(err error) bool {
+	var errResp *github.ErrorResponse
+	if !errors.As(err, &errResp) || errResp.Response == nil || errResp.Response.StatusCode != http.StatusUnprocessableEntity {
+		return false
+	}
+	for _, e := range errResp.Errors {
+		if e.Code == "already_exists" {
+			return true
+		}
+	}
+	return false
+}
+
+// labelColorAndDescription returns the color and description of the entry for a label request.
+// Fields the entry does not set are nil, so they are not changed when updating a label.
+func labelColorAndDescription(entry sizer.ConfigEntry) (*string, *string) {
+	var color, description *string
+	if entry.Color != "" {
+		color = github.Ptr(normalizeLabelColor(entry.Color))
+	}
+	if entry.Description != "" {
+		description = github.Ptr(entry.Description)
+	}
+	return color, description
+}
+
+// labelNeedsUpdate checks if the color or description of a label differ from the entry.
+func labelNeedsUpdate(label *github.Label, entry sizer.ConfigEntry) bool {
+	if entry.Color != "" && normalizeLabelColor(entry.Color) != normalizeLabelColor(label.GetColor()) {
+		return true
+	}
+	return entry.Description != "" && entry.Description != label.GetDescription()
+}
+
+// normalizeLabelColor converts a hex color into the format used by GitHub, without '#' and in lower case.
+func normalizeLabelColor(color string) string {
+	return strings.ToLower(strings.TrimPrefix(color, "#"))
+}
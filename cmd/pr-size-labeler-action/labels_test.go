@@ -0,0 +1,125 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/cbrgm/pr-size-labeler-action/pkg/sizer"
+	"github.com/google/go-github/v90/github"
+)
+
+func TestLabelNeedsUpdate(t *testing.T) {
+	label := &github.Label{Name: "size/xs", Color: "3CBF00", Description: github.Ptr("Extra small")}
+
+	tests := []struct {
+		name  string
+		entry sizer.ConfigEntry
+		want  bool
+	}{
+		{"NothingConfigured", sizer.ConfigEntry{}, false},
+		{"SameColor", sizer.ConfigEntry{Color: "#3cbf00"}, false},
+		{"DifferentColor", sizer.ConfigEntry{Color: "c32607"}, true},
+		{"SameDescription", sizer.ConfigEntry{Description: "Extra small"}, false},
+		{"DifferentDescription", sizer.ConfigEntry{Description: "Tiny"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := labelNeedsUpdate(label, tt.entry); got != tt.want {
+				t.Errorf("labelNeedsUpdate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLabelColorAndDescription(t *testing.T) {
+	color, description := labelColorAndDescription(sizer.ConfigEntry{Color: "#3CBF00"})
+	if color == nil || *color != "3cbf00" || description != nil {
+		t.Errorf("labelColorAndDescription() = %v, %v, want 3cbf00 and no description", color, description)
+	}
+
+	color, description = labelColorAndDescription(sizer.ConfigEntry{Description: "Extra small"})
+	if color != nil || description == nil || *description != "Extra small" {
+		t.Errorf("labelColorAndDescription() = %v, %v, want no color and the description", color, description)
+	}
+}
+
+func TestLabelStyle(t *testing.T) {
+	labelConfigs := []sizer.ConfigEntry{
+		{Size: "s", Labels: []string{"size/s"}},
+		{Size: "m", Labels: []string{"size/m", "pairing-wanted"}, Color: "7f7203"},
+		{Size: "l", Labels: []string{"size/l", "pairing-wanted"}, Color: "a14c05"},
+	}
+
+	tests := []struct {
+		name      string
+		label     string
+		wantColor string
+	}{
+		{"NoStyle", "size/s", ""},
+		{"OwnEntry", "size/l", "a14c05"},
+		{"FirstEntryWins", "pairing-wanted", "7f7203"},
+		{"UnknownLabel", "bug", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := labelStyle(labelConfigs, tt.label); got.Color != tt.wantColor {
+				t.Errorf("labelStyle(%v) color = %v, want %v", tt.label, got.Color, tt.wantColor)
+			}
+		})
+	}
+}
+
+func TestLabelSyncerSyncLabels(t *testing.T) {
+	config := Config{Config: sizer.Config{LabelConfigs: []sizer.ConfigEntry{
+		{Size: "xs", Labels: []string{"size/xs"}, Color: "3cbf00"},
+		{Size: "s", Labels: []string{"size/s"}, Color: "5d9801"},
+		{Size: "m", Labels: []string{"size/m"}, Color: "7f7203"},
+		{Size: "l", Labels: []string{"size/l"}},
+	}}}
+
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.EscapedPath())
+		switch {
+		case r.Method == http.MethodGet:
+			_ = json.NewEncoder(w).Encode([]map[string]string{
+				{"name": "Size/XS", "color": "3cbf00"},
+				{"name": "size/s", "color": "000000"},
+			})
+		case r.Method == http.MethodPost:
+			// Another run created the label in the meantime
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"message": "Validation Failed",
+				"errors":  []map[string]string{{"resource": "Label", "code": "already_exists", "field": "name"}},
+			})
+		default:
+			_ = json.NewEncoder(w).Encode(map[string]string{"name": "size/s"})
+		}
+	}))
+	defer server.Close()
+
+	serverURL := server.URL + "/"
+	client, err := github.NewClient(github.WithURLs(&serverURL, &serverURL))
+	if err != nil {
+		t.Fatalf("creating client: %v", err)
+	}
+
+	ls := NewLabelSyncer(t.Context(), &GitHubClientWrapper{client: client}, "owner", "repo", config, false)
+	if err := ls.SyncLabels(); err != nil {
+		t.Fatalf("SyncLabels() error = %v", err)
+	}
+	want := []string{
+		"GET /repos/owner/repo/labels",
+		"PATCH /repos/owner/repo/labels/size%2Fs",
+		"POST /repos/owner/repo/labels",
+	}
+	if !reflect.DeepEqual(requests, want) {
+		t.Errorf("SyncLabels() sent %v, want %v", requests, want)
+	}
+}
@@ -0,0 +1,259 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/cbrgm/pr-size-labeler-action/pkg/sizer"
+)
+
+// SizeCmd holds the arguments of the size subcommand.
+type SizeCmd struct {
+	Base string `arg:"--base" default:"origin/main" help:"ref to compare the working tree against, using its merge base with HEAD"`
+}
+
+// runSize sizes the changes of the local working tree against the merge base with the given ref and prints the result.
+func runSize(configFilePath, base string, fetcher ConfigFetcher) error {
+	config, err := loadConfig(configFilePath, fetcher)
+	if err != nil {
+		return fmt.Errorf("loading configuration: %w", err)
+	}
+
+	files, err := gitDiffFiles(base)
+	if err != nil {
+		return err
+	}
+	commits, err := gitCommitCount(base)
+	if err != nil {
+		return err
+	}
+
+	var attributes sizer.LinguistAttributes
+	if config.RespectGitattributes {
+		attributes, err = readGitAttributes()
+		if err != nil {
+			return err
+		}
+	}
+
+	result := sizer.CalculateWithCommits(files, commits, config.Config, attributes)
+	if result.WhenErr != nil {
+		logger.Warningf("Evaluating when expressions failed, falling back to the thresholds: %v", result.WhenErr)
+	}
+	printSizeReport(result, config.LabelConfigs)
+	return nil
+}
+
+// printSizeReport prints the size report as plain text.
+func printSizeReport(result sizer.Result, labelConfigs []sizer.ConfigEntry) {
+	fmt.Printf("Size: %s\n", result.Entry.Size)
+	fmt.Printf("Labels: %s\n", strings.Join(result.Entry.Labels, ", "))
+	for _, paramName := range reportedParams(labelConfigs) {
+		fmt.Printf("%s: %d (size %s)\n", paramTitles[paramName], result.Count(paramName), result.EntryFor(labelConfigs, paramName).Size)
+	}
+	fmt.Printf("Decided by: %s\n", result.DecidedBy(labelConfigs))
+	for _, scope := range result.Scopes {
+		fmt.Printf("Scope %s: %s (%d lines, %d files, labels %s)\n", scope.Scope.Name, scope.Result.Entry.Size, scope.Result.Lines, scope.Result.Files, strings.Join(scope.Result.Entry.Labels, ", "))
+	}
+	for _, filename := range result.ExcludedFiles {
+		fmt.Printf("Excluded: %s\n", filename)
+	}
+}
+
+// gitDiffFiles returns the files changed in the working tree compared to the merge base of HEAD and the given ref,
+// including untracked files.
+func gitDiffFiles(base string) ([]sizer.FileChange, error) {
+	mergeBase, err := runGit("merge-base", base, "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	mergeBase = strings.TrimSpace(mergeBase)
+
+	numstat, err := runGit("diff", "--numstat", "-z", "-M", mergeBase)
+	if err != nil {
+		return nil, err
+	}
+	nameStatus, err := runGit("diff", "--name-status", "-z", "-M", mergeBase)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := parseNumstat(numstat)
+	if err != nil {
+		return nil, err
+	}
+	statuses := parseNameStatus(nameStatus)
+	for i := range files {
+		files[i].Status = statuses[files[i].Filename]
+	}
+
+	untracked, err := gitUntrackedFiles()
+	if err != nil {
+		return nil, err
+	}
+	return append(files, untracked...), nil
+}
+
+// gitUntrackedFiles returns the files not yet added to git as added files, skipping ignored ones.
+func gitUntrackedFiles() ([]sizer.FileChange, error) {
+	root, err := runGit("rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, err
+	}
+	root = strings.TrimSpace(root)
+
+	output, err := runGit("-C", root, "ls-files", "--others", "--exclude-standard", "-z")
+	if err != nil {
+		return nil, err
+	}
+
+	var files []sizer.FileChange
+	for _, filename := range strings.Split(output, "\x00") {
+		if filename == "" {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(root, filename))
+		if err != nil {
+			return nil, err
+		}
+		lines := countLines(content)
+		files = append(files, sizer.FileChange{Filename: filename, Status: "added", Additions: lines, Changes: lines})
+	}
+	return files, nil
+}
+
+// countLines counts the lines of a file like git diff does. Binary files, which contain a NUL byte, have no lines.
+func countLines(content []byte) int {
+	if bytes.IndexByte(content, 0) >= 0 {
+		return 0
+	}
+	lines := bytes.Count(content, []byte("\n"))
+	if len(content) > 0 && content[len(content)-1] != '\n' {
+		lines++
+	}
+	return lines
+}
+
+// gitCommitCount returns the number of commits on HEAD since its merge base with the given ref.
+func gitCommitCount(base string) (int, error) {
+	output, err := runGit("rev-list", "--count", base+"..HEAD")
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(strings.TrimSpace(output))
+}
+
+// readGitAttributes reads the .gitattributes file at the root of the local repository.
+// A missing file results in no attributes.
+func readGitAttributes() (sizer.LinguistAttributes, error) {
+	root, err := runGit("rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := os.ReadFile(filepath.Join(strings.TrimSpace(root), GitAttributesPath))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return sizer.ParseGitAttributes(string(content)), nil
+}
+
+// runGit runs a git command in the current directory and returns its output.
+func runGit(args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("git", args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
+
+// parseNumstat parses the output of 'git diff --numstat -z'.
+// Renamed files are reported with their new name, binary files with zero lines.
+func parseNumstat(output string) ([]sizer.FileChange, error) {
+	var files []sizer.FileChange
+	fields := strings.Split(output, "\x00")
+	for i := 0; i < len(fields); i++ {
+		if fields[i] == "" {
+			continue
+		}
+
+		parts := strings.SplitN(fields[i], "\t", 3)
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("unexpected numstat line %q", fields[i])
+		}
+
+		filename := parts[2]
+		if filename == "" {
+			// Renames and copies are followed by the old and the new name
+			if i+2 >= len(fields) {
+				return nil, fmt.Errorf("unexpected end of numstat output after %q", fields[i])
+			}
+			filename = fields[i+2]
+			i += 2
+		}
+
+		additions, deletions := parseNumstatCount(parts[0]), parseNumstatCount(parts[1])
+		files = append(files, sizer.FileChange{
+			Filename:  filename,
+			Additions: additions,
+			Deletions: deletions,
+			Changes:   additions + deletions,
+		})
+	}
+	return files, nil
+}
+
+// parseNumstatCount parses a line count of the numstat output, which is "-" for binary files.
+func parseNumstatCount(value string) int {
+	count, err := strconv.Atoi(value)
+	if err != nil {
+		return 0
+	}
+	return count
+}
+
+// parseNameStatus parses the output of 'git diff --name-status -z' into GitHub file statuses by filename.
+func parseNameStatus(output string) map[string]string {
+	statuses := make(map[string]string)
+	fields := strings.Split(output, "\x00")
+	for i := 0; i+1 < len(fields); i += 2 {
+		status := fields[i]
+		if status == "" {
+			continue
+		}
+
+		switch status[0] {
+		case 'R', 'C':
+			// Renames and copies are followed by the old and the new name
+			if i+2 >= len(fields) {
+				return statuses
+			}
+			if status[0] == 'R' {
+				statuses[fields[i+2]] = "renamed"
+			} else {
+				statuses[fields[i+2]] = "copied"
+			}
+			i++
+		case 'A':
+			statuses[fields[i+1]] = "added"
+		case 'D':
+			statuses[fields[i+1]] = "removed"
+		default:
+			statuses[fields[i+1]] = "modified"
+		}
+	}
+	return statuses
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/cbrgm/pr-size-labeler-action/pkg/sizer"
+)
+
+func TestParseNumstat(t *testing.T) {
+	output := "10\t2\tmain.go\x00-\t-\tlogo.png\x005\t1\t\x00old/name.go\x00new/name.go\x00"
+
+	got, err := parseNumstat(output)
+	if err != nil {
+		t.Fatalf("parseNumstat() error = %v", err)
+	}
+
+	want := []sizer.FileChange{
+		{Filename: "main.go", Additions: 10, Deletions: 2, Changes: 12},
+		{Filename: "logo.png"},
+		{Filename: "new/name.go", Additions: 5, Deletions: 1, Changes: 6},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseNumstat() = %+v, want %+v", got, want)
+	}
+
+	if _, err := parseNumstat("garbage\x00"); err == nil {
+		t.Errorf("parseNumstat() expected an error for malformed output")
+	}
+}
+
+func TestParseNameStatus(t *testing.T) {
+	output := "M\x00main.go\x00A\x00added.go\x00D\x00removed.go\x00R087\x00old/name.go\x00new/name.go\x00C100\x00a.go\x00b.go\x00T\x00link\x00"
+
+	want := map[string]string{
+		"main.go":     "modified",
+		"added.go":    "added",
+		"removed.go":  "removed",
+		"new/name.go": "renamed",
+		"b.go":        "copied",
+		"link":        "modified",
+	}
+	if got := parseNameStatus(output); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseNameStatus() = %v, want %v", got, want)
+	}
+}
+
+func TestCountLines(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    int
+	}{
+		{"Empty", "", 0},
+		{"TrailingNewline", "a\nb\n", 2},
+		{"NoTrailingNewline", "a\nb", 2},
+		{"Binary", "PNG\x00\x01\n", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := countLines([]byte(tt.content)); got != tt.want {
+				t.Errorf("countLines() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// EnvGitHubActions is set to "true" when running in GitHub Actions.
+const EnvGitHubActions = "GITHUB_ACTIONS"
+
+// logger is the logger used by the action.
+var logger = NewLogger(os.Stdout, os.Getenv(EnvGitHubActions) == "true")
+
+// Logger writes warnings, errors and groups as GitHub Actions workflow commands, so they show up as annotations.
+// Outside of GitHub Actions, it writes plain messages instead.
+type Logger struct {
+	mu      sync.Mutex
+	w       io.Writer
+	actions bool
+	grouped bool // grouped is set for loggers writing into a group, as GitHub Actions does not support nested groups.
+}
+
+// NewLogger creates a new Logger writing to w. If actions is false, plain messages are written.
+func NewLogger(w io.Writer, actions bool) *Logger {
+	return &Logger{w: w, actions: actions}
+}
+
+// Infof writes an informational message.
+func (l *Logger) Infof(format string, args ...any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	_, _ = fmt.Fprintf(l.w, format+"\n", args...)
+}
+
+// Warningf writes a warning.
+func (l *Logger) Warningf(format string, args ...any) {
+	l.command("warning", "Warning: ", "", 0, fmt.Sprintf(format, args...))
+}
+
+// Errorf writes an error.
+func (l *Logger) Errorf(format string, args ...any) {
+	l.command("error", "Error: ", "", 0, fmt.Sprintf(format, args...))
+}
+
+// ErrorAt writes an error pointing at a line of a file. A line of 0 points at the whole file.
+// Outside of GitHub Actions, the message is prefixed with "file:line: ".
+func (l *Logger) ErrorAt(file string, line int, format string, args ...any) {
+	l.command("error", "", file, line, fmt.Sprintf(format, args...))
+}
+
+// Group starts a collapsible group of log lines with the given title.
+func (l *Logger) Group(title string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.startGroup(title)
+}
+
+// EndGroup ends the current group.
+func (l *Logger) EndGroup() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.endGroup()
+}
+
+// BufferedGroup returns a logger keeping its lines until flush writes them as one collapsible group with the given title.
+// This way, the lines of pull requests processed at the same time are not interleaved.
+func (l *Logger) BufferedGroup(title string) (group *Logger, flush func()) {
+	var buf bytes.Buffer
+	group = &Logger{w: &buf, actions: l.actions, grouped: true}
+	return group, func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		group.mu.Lock()
+		defer group.mu.Unlock()
+
+		l.startGroup(title)
+		_, _ = buf.WriteTo(l.w)
+		l.endGroup()
+	}
+}
+
+// startGroup writes the start of a group. Inside a group, the title is written as a plain line instead.
+func (l *Logger) startGroup(title string) {
+	if l.actions && !l.grouped {
+		_, _ = fmt.Fprintf(l.w, "::group::%s\n", escapeWorkflowCommandData(title))
+		return
+	}
+	_, _ = fmt.Fprintln(l.w, title)
+}
+
+// endGroup writes the end of a group.
+func (l *Logger) endGroup() {
+	if l.actions && !l.grouped {
+		_, _ = fmt.Fprintln(l.w, "::endgroup::")
+	}
+}
+
+// command writes a workflow command, or a plain message with the given prefix outside of GitHub Actions.
+func (l *Logger) command(name, plainPrefix, file string, line int, message string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.actions {
+		_, _ = fmt.Fprintln(l.w, plainPrefix+location(file, line)+message)
+		return
+	}
+
+	var properties []string
+	if file != "" {
+		properties = append(properties, "file="+escapeWorkflowCommandProperty(file))
+	}
+	if file != "" && line > 0 {
+		properties = append(properties, "line="+strconv.Itoa(line))
+	}
+	if len(properties) > 0 {
+		name += " " + strings.Join(properties, ",")
+	}
+	_, _ = fmt.Fprintf(l.w, "::%s::%s\n", name, escapeWorkflowCommandData(message))
+}
+
+// location formats a file and line as a prefix for plain messages, such as "config.yml:3: ".
+func location(file string, line int) string {
+	switch {
+	case file == "":
+		return ""
+	case line > 0:
+		return fmt.Sprintf("%s:%d: ", file, line)
+	default:
+		return file + ": "
+	}
+}
+
+// escapeWorkflowCommandData escapes a message for a GitHub Actions workflow command, so it can span several lines.
+func escapeWorkflowCommandData(message string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(message)
+}
+
+// escapeWorkflowCommandProperty escapes a property value of a GitHub Actions workflow command.
+func escapeWorkflowCommandProperty(value string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(value)
+}
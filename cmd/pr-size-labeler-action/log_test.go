@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestLogger(t *testing.T) {
+	tests := []struct {
+		name        string
+		log         func(l *Logger)
+		wantActions string
+		wantPlain   string
+	}{
+		{
+			"Warning",
+			func(l *Logger) { l.Warningf("event %q is not supported", "push") },
+			"::warning::event \"push\" is not supported\n",
+			"Warning: event \"push\" is not supported\n",
+		},
+		{
+			"MultiLineError",
+			func(l *Logger) { l.Errorf("line 1\nline 2") },
+			"::error::line 1%0Aline 2\n",
+			"Error: line 1\nline 2\n",
+		},
+		{
+			"ErrorAtLine",
+			func(l *Logger) { l.ErrorAt(".github/pull-request-size.yml", 3, "invalid pattern %q", "[abc") },
+			"::error file=.github/pull-request-size.yml,line=3::invalid pattern \"[abc\"\n",
+			".github/pull-request-size.yml:3: invalid pattern \"[abc\"\n",
+		},
+		{
+			"ErrorAtUnknownLine",
+			func(l *Logger) { l.ErrorAt("config.yml", 0, "100%% broken") },
+			"::error file=config.yml::100%25 broken\n",
+			"config.yml: 100% broken\n",
+		},
+		{
+			"Group",
+			func(l *Logger) {
+				l.Group("Dry run")
+				l.EndGroup()
+			},
+			"::group::Dry run\n::endgroup::\n",
+			"Dry run\n",
+		},
+		{
+			"Info",
+			func(l *Logger) { l.Infof("pull request #%d has size %s", 1, "m") },
+			"pull request #1 has size m\n",
+			"pull request #1 has size m\n",
+		},
+		{
+			"BufferedGroup",
+			func(l *Logger) {
+				group, flush := l.BufferedGroup("Pull request #1")
+				group.Infof("size m")
+				l.Infof("size l")
+				group.Group("Dry run")
+				group.Warningf("approximate")
+				group.EndGroup()
+				flush()
+			},
+			"size l\n::group::Pull request #1\nsize m\nDry run\n::warning::approximate\n::endgroup::\n",
+			"size l\nPull request #1\nsize m\nDry run\nWarning: approximate\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var actions, plain bytes.Buffer
+			tt.log(NewLogger(&actions, true))
+			tt.log(NewLogger(&plain, false))
+
+			if actions.String() != tt.wantActions {
+				t.Errorf("GitHub Actions output = %q, want %q", actions.String(), tt.wantActions)
+			}
+			if plain.String() != tt.wantPlain {
+				t.Errorf("plain output = %q, want %q", plain.String(), tt.wantPlain)
+			}
+		})
+	}
+}
+
+func TestEscapeWorkflowCommandData(t *testing.T) {
+	got := escapeWorkflowCommandData("line 1: 100% wrong\r\nline 2")
+	want := "line 1: 100%25 wrong%0D%0Aline 2"
+	if got != want {
+		t.Errorf("escapeWorkflowCommandData() = %q, want %q", got, want)
+	}
+}
+
+func TestEscapeWorkflowCommandProperty(t *testing.T) {
+	got := escapeWorkflowCommandProperty("dir,name:1.yml")
+	want := "dir%2Cname%3A1.yml"
+	if got != want {
+		t.Errorf("escapeWorkflowCommandProperty() = %q, want %q", got, want)
+	}
+}
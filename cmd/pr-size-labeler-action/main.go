@@ -2,16 +2,20 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io/fs"
+	"net/http"
 	"os"
-	"path/filepath"
 	"runtime"
 	"slices"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/alexflint/go-arg"
+	"github.com/cbrgm/pr-size-labeler-action/pkg/sizer"
 	"github.com/google/go-github/v90/github"
 	"golang.org/x/oauth2"
 	"gopkg.in/yaml.v3"
@@ -26,13 +30,68 @@ var (
 )
 
 // EnvArgs struct holds the required environment variables.
+// The GitHub variables are only required when labeling a pull request, not for subcommands.
 type EnvArgs struct {
-	GithubToken         string `arg:"env:GITHUB_TOKEN,required"`
-	EventName           string `arg:"env:GITHUB_EVENT_NAME,required"`
-	PrNumber            string `arg:"env:PULL_REQUEST_NUMBER,required"`
-	RepoName            string `arg:"env:GITHUB_REPOSITORY,required"`
+	GithubToken         string `arg:"env:GITHUB_TOKEN"`
+	EventName           string `arg:"env:GITHUB_EVENT_NAME"`
+	PrNumber            string `arg:"env:PULL_REQUEST_NUMBER"`
+	RepoName            string `arg:"env:GITHUB_REPOSITORY"`
 	ConfigFilePath      string `arg:"env:CONFIG_FILE_PATH"`
 	GitHubEnterpriseUrl string `arg:"env:GITHUB_ENTERPRISE_URL"`
+	// GitHubAppID, GitHubAppPrivateKey and GitHubAppInstallationID authenticate as a GitHub App instead of with a token.
+	GitHubAppID             string `arg:"env:GITHUB_APP_ID"`
+	GitHubAppPrivateKey     string `arg:"env:GITHUB_APP_PRIVATE_KEY"`
+	GitHubAppInstallationID string `arg:"env:GITHUB_APP_INSTALLATION_ID"`
+	// ConfigFromBaseBranch reads the configuration file from the base branch through the API.
+	ConfigFromBaseBranch bool `arg:"env:CONFIG_FROM_BASE_BRANCH"`
+	// DryRun computes and prints the label changes without modifying the pull request.
+	DryRun bool `arg:"env:DRY_RUN"`
+	// BatchConcurrency is the number of pull requests processed at the same time for schedule and workflow_dispatch events.
+	// If it is not set, DefaultBatchConcurrency is used.
+	BatchConcurrency int `arg:"env:BATCH_CONCURRENCY"`
+	// FailOnError fails the action on errors. If false, errors are reported as warnings and the action succeeds.
+	FailOnError bool `arg:"env:FAIL_ON_ERROR" default:"true"`
+
+	Validate *ValidateCmd `arg:"subcommand:validate" help:"validate a configuration file and exit"`
+	Size     *SizeCmd     `arg:"subcommand:size" help:"size the local git changes without GitHub and exit"`
+}
+
+// ValidateCmd holds the arguments of the validate subcommand.
+type ValidateCmd struct {
+	ConfigFilePath string `arg:"positional" help:"path to the configuration file, defaults to CONFIG_FILE_PATH or .github/pull-request-size.yml"`
+}
+
+// missingRequiredEnv returns the environment variables required for labeling a pull request that are not set.
+// The pull request number is not required for events relabeling all open pull requests,
+// and the token is not required when any of the GitHub App credentials is set. The app credentials must be set together.
+func (args EnvArgs) missingRequiredEnv() []string {
+	useApp := args.usesGitHubApp()
+	required := []struct {
+		name     string
+		value    string
+		required bool
+	}{
+		{"GITHUB_TOKEN", args.GithubToken, !useApp},
+		{"GITHUB_APP_ID", args.GitHubAppID, useApp},
+		{"GITHUB_APP_PRIVATE_KEY", args.GitHubAppPrivateKey, useApp},
+		{"GITHUB_APP_INSTALLATION_ID", args.GitHubAppInstallationID, useApp},
+		{"GITHUB_EVENT_NAME", args.EventName, true},
+		{"PULL_REQUEST_NUMBER", args.PrNumber, !isBatchEvent(args.EventName)},
+		{"GITHUB_REPOSITORY", args.RepoName, true},
+	}
+
+	var missing []string
+	for _, env := range required {
+		if env.required && env.value == "" {
+			missing = append(missing, env.name)
+		}
+	}
+	return missing
+}
+
+// usesGitHubApp checks if any of the GitHub App credentials is set.
+func (args EnvArgs) usesGitHubApp() bool {
+	return args.GitHubAppID != "" || args.GitHubAppPrivateKey != "" || args.GitHubAppInstallationID != ""
 }
 
 // Version returns a formatted string with application version details.
@@ -43,35 +102,92 @@ func (EnvArgs) Version() string {
 // Constants for default configuration and event names.
 const (
 	DefaultConfigPath = ".github/pull-request-size.yml"
-	ParamNameFiles    = "files"
-	ParamNameDiff     = "diff"
-)
+	// GitAttributesPath is the path of the attributes file read from the repository root.
+	GitAttributesPath = ".gitattributes"
 
-// ConfigEntry defines a single configuration entry for label assignment.
-type ConfigEntry struct {
-	Size   string   `yaml:"size"`
-	Diff   int      `yaml:"diff"`
-	Files  int      `yaml:"files"`
-	Labels []string `yaml:"labels"` // Updated to support multiple labels
-}
+	// MaxPullRequestFiles is the maximum number of files GitHub lists for a pull request.
+	MaxPullRequestFiles = 3000
+	// FilesPerPage is the page size used when listing pull request files.
+	FilesPerPage = 100
+)
 
 // Config struct holds the entire configuration for label assignment.
+// The sizing configuration is embedded, the other fields control how the size is reported.
 type Config struct {
-	ExcludeFiles   []string      `yaml:"exclude_files"`
-	LabelConfigs   []ConfigEntry `yaml:"label_configs"`
-	AddedLinesOnly bool          `yaml:"added_lines_only"`
+	sizer.Config `yaml:",inline"`
+
+	// RespectGitattributes excludes files marked linguist-generated or linguist-vendored in .gitattributes.
+	RespectGitattributes bool `yaml:"respect_gitattributes"`
+	// StickyComment posts a comment explaining the size and keeps it updated on later runs.
+	StickyComment bool `yaml:"sticky_comment"`
+	// CommitStatus reports the size as a commit status on the head commit.
+	CommitStatus bool `yaml:"commit_status"`
+	// FailAbove sets the commit status to failure for pull requests bigger than this size.
+	FailAbove string `yaml:"fail_above"`
+	// PathLabels add area labels for the paths a pull request changes, alongside the size labels.
+	PathLabels []PathLabel `yaml:"path_labels"`
+	// Extends references a shared configuration this configuration is merged into, see ParseConfigRef.
+	Extends string `yaml:"extends"`
+
+	node *yaml.Node // node is the parsed YAML document, used to report line numbers.
+}
+
+// DefaultConfig returns the built-in configuration used when no configuration file exists.
+func DefaultConfig() Config {
+	return Config{Config: sizer.Config{LabelConfigs: sizer.DefaultLabelConfigs()}}
 }
 
 // GitHubClientWrapper wraps the GitHub client for ease of testing and abstraction.
 type GitHubClientWrapper struct {
-	client *github.Client
+	client    *github.Client
+	appClient *github.Client // appClient is authenticated as the GitHub App, or nil when authenticated with a token.
+
+	loginOnce sync.Once
+	login     string
+	loginErr  error
 }
 
-// NewGitHubClientWrapper creates a new wrapper for the GitHub client.
-func NewGitHubClientWrapper(token, gitHubEnterpriseUrl string) *GitHubClientWrapper {
-	ctx := context.Background()
+// NewGitHubClientWrapper creates a new wrapper for the GitHub client authenticated with a token.
+func NewGitHubClientWrapper(token, gitHubEnterpriseUrl string) (*GitHubClientWrapper, error) {
 	ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token})
-	tc := oauth2.NewClient(ctx, ts)
+	client, err := newGitHubClient(context.Background(), ts, gitHubEnterpriseUrl)
+	if err != nil {
+		return nil, err
+	}
+	return &GitHubClientWrapper{client: client}, nil
+}
+
+// newGitHubClientWrapperFromArgs creates a new wrapper for the GitHub client,
+// authenticated as a GitHub App if its credentials are set or with the token otherwise.
+func newGitHubClientWrapperFromArgs(args EnvArgs) (*GitHubClientWrapper, error) {
+	if !args.usesGitHubApp() {
+		return NewGitHubClientWrapper(args.GithubToken, args.GitHubEnterpriseUrl)
+	}
+	appID, err := strconv.ParseInt(args.GitHubAppID, 10, 64)
+	if err != nil {
+		return nil, withKind(ErrConfig, fmt.Errorf("parsing GitHub App ID: %w", err))
+	}
+	installationID, err := strconv.ParseInt(args.GitHubAppInstallationID, 10, 64)
+	if err != nil {
+		return nil, withKind(ErrConfig, fmt.Errorf("parsing GitHub App installation ID: %w", err))
+	}
+
+	credentials := GitHubAppCredentials{
+		AppID:          appID,
+		PrivateKey:     []byte(args.GitHubAppPrivateKey),
+		InstallationID: installationID,
+	}
+	return NewGitHubAppClientWrapper(credentials, args.GitHubEnterpriseUrl)
+}
+
+// newGitHubClient creates a GitHub client authenticated with the tokens of the given source.
+// Without a token source, requests are anonymous. Idempotent requests failing temporarily are retried with backoff.
+func newGitHubClient(ctx context.Context, ts oauth2.TokenSource, gitHubEnterpriseUrl string) (*github.Client, error) {
+	tc := &http.Client{}
+	if ts != nil {
+		tc = oauth2.NewClient(ctx, ts)
+	}
+	tc.Transport = newRetryTransport(tc.Transport)
 
 	opts := []github.ClientOptionsFunc{github.WithHTTPClient(tc)}
 	if gitHubEnterpriseUrl != "" {
@@ -80,11 +196,9 @@ func NewGitHubClientWrapper(token, gitHubEnterpriseUrl string) *GitHubClientWrap
 
 	client, err := github.NewClient(opts...)
 	if err != nil {
-		fmt.Printf("Failed to create GitHub client: %v\n", err)
-		os.Exit(1)
+		return nil, withKind(ErrConfig, err)
 	}
-
-	return &GitHubClientWrapper{client: client}
+	return client, nil
 }
 
 // PullRequestProcessor handles the processing of a single pull request.
@@ -94,84 +208,210 @@ type PullRequestProcessor struct {
 	repoName      string
 	prNumber      int
 	config        Config
+	dryRun        bool
+	log           *Logger
 	ctx           context.Context
 }
 
-// NewPullRequestProcessor creates a new PullRequestProcessor instance.
-func NewPullRequestProcessor(ctx context.Context, clientWrapper *GitHubClientWrapper, repoOwner, repoName string, prNumber int, config Config) *PullRequestProcessor {
+// NewPullRequestProcessor creates a new PullRequestProcessor instance logging to the action's logger.
+// In dry-run mode, the processor prints the planned changes instead of modifying the pull request.
+func NewPullRequestProcessor(ctx context.Context, clientWrapper *GitHubClientWrapper, repoOwner, repoName string, prNumber int, config Config, dryRun bool) *PullRequestProcessor {
 	return &PullRequestProcessor{
 		clientWrapper: clientWrapper,
 		repoOwner:     repoOwner,
 		repoName:      repoName,
 		prNumber:      prNumber,
 		config:        config,
+		dryRun:        dryRun,
+		log:           logger,
 		ctx:           ctx,
 	}
 }
 
 // ProcessPullRequest processes the files of a pull request and applies labels accordingly.
-func (prp *PullRequestProcessor) ProcessPullRequest() {
+// It returns the size of the pull request.
+func (prp *PullRequestProcessor) ProcessPullRequest() (sizer.Result, error) {
+	pr, _, err := prp.clientWrapper.client.PullRequests.Get(prp.ctx, prp.repoOwner, prp.repoName, prp.prNumber)
+	if err != nil {
+		return sizer.Result{}, fmt.Errorf("fetching pull request: %w", err)
+	}
+
 	files, err := prp.fetchPullRequestFiles()
 	if err != nil {
-		exitOnError("fetching pull request files", err)
-		return
+		return sizer.Result{}, fmt.Errorf("fetching pull request files: %w", err)
 	}
 
-	numberOfFiles, numberOfLines := calculateSizeAndDiff(files, prp.config)
-	size, diff := mapNumberOfChangesToSize(numberOfFiles, numberOfLines, prp.config)
-	biggestEntry := getBiggestEntry(prp.config.LabelConfigs, size, diff)
+	result, err := prp.calculateSize(pr, files)
+	if err != nil {
+		return sizer.Result{}, fmt.Errorf("calculating pull request size: %w", err)
+	}
 
-	err = prp.updatePullRequestLabel(biggestEntry)
+	entries := append([]sizer.ConfigEntry{result.Entry}, scopeEntries(result)...)
+	err = prp.updatePullRequestLabel(pr, entries, append(prp.pathLabels(pr, files), keptScopeLabels(pr, prp.config, result)...))
 	if err != nil {
-		exitOnError("updating pull request label", err)
+		return sizer.Result{}, fmt.Errorf("updating pull request label: %w", err)
+	}
+
+	if prp.config.StickyComment && prp.dryRun {
+		prp.log.Group(fmt.Sprintf("Dry run: would update the size comment of pull request #%d", prp.prNumber))
+		prp.log.Infof("%s", renderSizeReport(result, prp.config.LabelConfigs))
+		prp.log.EndGroup()
+	} else if prp.config.StickyComment {
+		err = prp.updateSizeComment(result)
+		if err != nil {
+			return sizer.Result{}, fmt.Errorf("updating size comment: %w", err)
+		}
+	}
+
+	if prp.config.CommitStatus && prp.dryRun {
+		prp.log.Infof("Dry run: would set commit status %q of pull request #%d: %s", StatusContext, prp.prNumber, statusDescription(result))
+	} else if prp.config.CommitStatus {
+		err = prp.updateCommitStatus(pr, result)
+		if err != nil {
+			return sizer.Result{}, fmt.Errorf("updating commit status: %w", err)
+		}
 	}
+	return result, nil
+}
+
+// calculateSize calculates the size of the pull request and the matching configuration entry.
+func (prp *PullRequestProcessor) calculateSize(pr *github.PullRequest, files []*github.CommitFile) (sizer.Result, error) {
+	if isFileListTruncated(pr, files) {
+		prp.log.Warningf("Pull request changes %d files but only %d could be listed, size is approximate, exclude_files and when expressions are ignored and directories and renames are not counted", pr.GetChangedFiles(), len(files))
+		numberOfFiles, numberOfLines := calculateApproximateSizeAndDiff(pr, prp.config)
+		counts := sizer.Counts{Files: numberOfFiles, Lines: numberOfLines, Commits: pr.GetCommits(), Deletions: pr.GetDeletions()}
+		result := sizer.NewResultFromCounts(counts, prp.config.Config)
+		result.Approximate = true
+		return result, nil
+	}
+
+	var attributes sizer.LinguistAttributes
+	if prp.config.RespectGitattributes {
+		var err error
+		attributes, err = prp.fetchGitAttributes(pr.GetHead().GetSHA())
+		if err != nil {
+			return sizer.Result{}, fmt.Errorf("fetching .gitattributes: %w", err)
+		}
+	}
+	result := sizer.CalculateWithCommits(newFileChanges(files), pr.GetCommits(), prp.config.Config, attributes)
+	if result.WhenErr != nil {
+		prp.log.Warningf("Evaluating when expressions failed, falling back to the thresholds: %v", result.WhenErr)
+	}
+	return result, nil
 }
 
 func main() {
 	var args EnvArgs
-	arg.MustParse(&args)
+	p := arg.MustParse(&args)
 
-	if !isValidGitHubEventType(args.EventName) || !isValidRepoFormat(args.RepoName) {
-		return
+	if args.Validate == nil && args.Size == nil {
+		if missing := args.missingRequiredEnv(); len(missing) > 0 {
+			p.Fail(fmt.Sprintf("missing required environment variables: %s", strings.Join(missing, ", ")))
+		}
+	}
+
+	// Subcommands always fail on errors, fail_on_error only applies to labeling pull requests
+	failOnError := args.FailOnError || args.Validate != nil || args.Size != nil
+	os.Exit(exitStatus(run(args), failOnError))
+}
+
+// run runs the subcommand or labels the pull requests of the event.
+func run(args EnvArgs) error {
+	if args.Validate != nil {
+		filePath := args.Validate.ConfigFilePath
+		if filePath == "" {
+			filePath = getConfigFilePath(args.ConfigFilePath)
+		}
+		fetcher, err := newLocalConfigFetcher(args)
+		if err != nil {
+			return wrapError("creating GitHub client", err)
+		}
+		return wrapError("validating configuration", runValidate(filePath, fetcher))
+	}
+
+	if args.Size != nil {
+		fetcher, err := newLocalConfigFetcher(args)
+		if err != nil {
+			return wrapError("creating GitHub client", err)
+		}
+		return wrapError("sizing local changes", runSize(getConfigFilePath(args.ConfigFilePath), args.Size.Base, fetcher))
+	}
+
+	if !isValidGitHubEventType(args.EventName, args.PrNumber) || !isValidRepoFormat(args.RepoName) {
+		return nil
+	}
+
+	ctx := context.Background()
+	clientWrapper, err := newGitHubClientWrapperFromArgs(args)
+	if err != nil {
+		return wrapError("creating GitHub client", err)
+	}
+	repoOwner, repoName := parseRepoOwner(args.RepoName), parseRepoName(args.RepoName)
+
+	if args.isBatchRun() {
+		config, err := loadConfigForRepository(ctx, clientWrapper, repoOwner, repoName, args)
+		if err != nil {
+			return wrapError("loading configuration", err)
+		}
+
+		if err := NewLabelSyncer(ctx, clientWrapper, repoOwner, repoName, config, args.DryRun).SyncLabels(); err != nil {
+			return wrapError("syncing labels", err)
+		}
+		batchProcessor := NewBatchProcessor(ctx, clientWrapper, repoOwner, repoName, config, args.DryRun, args.BatchConcurrency)
+		return wrapError("processing open pull requests", batchProcessor.ProcessOpenPullRequests())
 	}
 
 	prNumber, err := strconv.Atoi(args.PrNumber)
 	if err != nil {
-		exitOnError("parsing pull request number", err)
-		return
+		return wrapError("parsing pull request number", err)
 	}
 
-	config, err := loadConfig(getConfigFilePath(args.ConfigFilePath))
+	config, err := loadConfigForPullRequest(ctx, clientWrapper, repoOwner, repoName, prNumber, args)
 	if err != nil {
-		exitOnError("loading configuration", err)
-		return
+		return wrapError("loading configuration", err)
 	}
 
-	ctx := context.Background()
-	clientWrapper := NewGitHubClientWrapper(args.GithubToken, args.GitHubEnterpriseUrl)
-	prProcessor := NewPullRequestProcessor(ctx, clientWrapper, parseRepoOwner(args.RepoName), parseRepoName(args.RepoName), prNumber, config)
-	prProcessor.ProcessPullRequest()
+	if err := NewLabelSyncer(ctx, clientWrapper, repoOwner, repoName, config, args.DryRun).SyncLabels(); err != nil {
+		return wrapError("syncing labels", err)
+	}
+	prProcessor := NewPullRequestProcessor(ctx, clientWrapper, repoOwner, repoName, prNumber, config, args.DryRun)
+	result, err := prProcessor.ProcessPullRequest()
+	if err != nil {
+		return wrapError("processing pull request", err)
+	}
+	return wrapError("writing step outputs", writeStepOutputs(result, config.LabelConfigs))
 }
 
-// isValidGitHubEventType checks if the event name is a valid pull request event.
-func isValidGitHubEventType(eventName string) bool {
-	allowedEvents := map[string]bool{
-		"pull_request":        true,
-		"pull_request_target": true,
+// runValidate validates a configuration file and logs every problem found.
+func runValidate(filePath string, fetcher ConfigFetcher) error {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return withKind(ErrConfig, err)
 	}
 
-	if allowedEvents[strings.ToLower(eventName)] {
+	if _, err := parseConfigFile(filePath, data, fetcher); err != nil {
+		return err
+	}
+
+	fmt.Printf("%s is valid\n", filePath)
+	return nil
+}
+
+// isValidGitHubEventType checks if the event can be processed. Any event with a pull request number
+// labels that pull request, and schedule and workflow_dispatch events without one relabel all open pull requests.
+func isValidGitHubEventType(eventName, prNumber string) bool {
+	if prNumber != "" || isBatchEvent(eventName) {
 		return true
 	}
 
-	fmt.Println("Event is not a valid pull request event, doing nothing")
+	logger.Warningf("Event %q has no pull request number and is not a schedule or workflow_dispatch event, doing nothing", eventName)
 	return false
 }
 
 // isValidRepoFormat checks if the repository name follows the 'owner/repository' format.
 func isValidRepoFormat(repoName string) bool {
 	if !isValidRepoNameFormat(repoName) {
-		fmt.Printf("Repository name is in the wrong format. Expected 'owner/repository'\n")
+		logger.Errorf("Repository name %q is in the wrong format. Expected 'owner/repository'", repoName)
 		return false
 	}
 	return true
@@ -185,179 +425,287 @@ func getConfigFilePath(providedPath string) string {
 	return providedPath
 }
 
+// loadConfigForPullRequest loads the configuration for a pull request.
+// If enabled, the configuration file is read from the base branch through the API, so a pull
+// request can not change its own thresholds. The local file is used as a fallback.
+func loadConfigForPullRequest(ctx context.Context, clientWrapper *GitHubClientWrapper, repoOwner, repoName string, prNumber int, args EnvArgs) (Config, error) {
+	filePath := getConfigFilePath(args.ConfigFilePath)
+	fetcher := newConfigFetcher(ctx, clientWrapper.client)
+	if !args.ConfigFromBaseBranch {
+		return loadConfig(filePath, fetcher)
+	}
+
+	pr, _, err := clientWrapper.client.PullRequests.Get(ctx, repoOwner, repoName, prNumber)
+	if err != nil {
+		return Config{}, err
+	}
+	return loadRemoteConfig(ctx, clientWrapper.client, repoOwner, repoName, filePath, pr.GetBase().GetRef(), fetcher)
+}
+
+// loadConfigForRepository loads the configuration used for all open pull requests of a repository.
+// If enabled, the configuration file is read from the default branch through the API.
+// The local file is used as a fallback.
+func loadConfigForRepository(ctx context.Context, clientWrapper *GitHubClientWrapper, repoOwner, repoName string, args EnvArgs) (Config, error) {
+	filePath := getConfigFilePath(args.ConfigFilePath)
+	fetcher := newConfigFetcher(ctx, clientWrapper.client)
+	if !args.ConfigFromBaseBranch {
+		return loadConfig(filePath, fetcher)
+	}
+
+	repo, _, err := clientWrapper.client.Repositories.Get(ctx, repoOwner, repoName)
+	if err != nil {
+		return Config{}, err
+	}
+	return loadRemoteConfig(ctx, clientWrapper.client, repoOwner, repoName, filePath, repo.GetDefaultBranch(), fetcher)
+}
+
+// loadRemoteConfig loads the configuration file from the repository at the given branch.
+// If the file does not exist on the branch, the local file is used.
+func loadRemoteConfig(ctx context.Context, client *github.Client, repoOwner, repoName, filePath, branch string, fetcher ConfigFetcher) (Config, error) {
+	data, found, err := fetchRemoteConfig(ctx, client, repoOwner, repoName, filePath, branch)
+	if err != nil {
+		return Config{}, err
+	}
+	if !found {
+		logger.Warningf("Configuration file %s not found on branch %s, falling back to the local file", filePath, branch)
+		return loadConfig(filePath, fetcher)
+	}
+	return parseConfigFile(filePath, data, fetcher)
+}
+
+// fetchRemoteConfig fetches the configuration file from the repository at the given ref.
+// It reports false if the file does not exist.
+func fetchRemoteConfig(ctx context.Context, client *github.Client, repoOwner, repoName, filePath, ref string) ([]byte, bool, error) {
+	file, _, resp, err := client.Repositories.GetContents(ctx, repoOwner, repoName, filePath, &github.RepositoryContentGetOptions{Ref: ref})
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	if file == nil {
+		return nil, false, withKind(ErrConfig, fmt.Errorf("%s is a directory", filePath))
+	}
+
+	content, err := file.GetContent()
+	if err != nil {
+		return nil, false, withKind(ErrConfig, err)
+	}
+	return []byte(content), true, nil
+}
+
 // loadConfig loads the configuration from the YAML file.
-func loadConfig(filePath string) (Config, error) {
-	var config Config
+// If the file at DefaultConfigPath does not exist, the built-in default configuration is used.
+// A missing file at any other path is a configuration error.
+func loadConfig(filePath string, fetcher ConfigFetcher) (Config, error) {
 	yamlFile, err := os.ReadFile(filePath)
+	if errors.Is(err, fs.ErrNotExist) && filePath == DefaultConfigPath {
+		logger.Infof("Configuration file %s not found, using the default configuration", filePath)
+		return DefaultConfig(), nil
+	}
 	if err != nil {
-		return config, err
+		return Config{}, withKind(ErrConfig, err)
 	}
-	err = yaml.Unmarshal(yamlFile, &config)
-	return config, err
+	return parseConfigFile(filePath, yamlFile, fetcher)
 }
 
-// fetchPullRequestFiles fetches the list of files in a pull request.
+// fetchPullRequestFiles fetches the list of files in a pull request, following pagination.
 func (prp *PullRequestProcessor) fetchPullRequestFiles() ([]*github.CommitFile, error) {
-	files, _, err := prp.clientWrapper.client.PullRequests.ListFiles(prp.ctx, prp.repoOwner, prp.repoName, prp.prNumber, nil)
-	return files, err
+	var allFiles []*github.CommitFile
+	opts := &github.ListOptions{PerPage: FilesPerPage}
+	for {
+		files, resp, err := prp.clientWrapper.client.PullRequests.ListFiles(prp.ctx, prp.repoOwner, prp.repoName, prp.prNumber, opts)
+		if err != nil {
+			return nil, err
+		}
+		allFiles = append(allFiles, files...)
+		if resp.NextPage == 0 || len(allFiles) >= MaxPullRequestFiles {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return allFiles, nil
 }
 
-// updatePullRequestLabel updates the labels of the pull request based on its size.
-func (prp *PullRequestProcessor) updatePullRequestLabel(entry ConfigEntry) error {
-	pr, _, err := prp.clientWrapper.client.PullRequests.Get(prp.ctx, prp.repoOwner, prp.repoName, prp.prNumber)
+// fetchGitAttributes fetches and parses the .gitattributes file at the given ref.
+// A missing file results in no attributes.
+func (prp *PullRequestProcessor) fetchGitAttributes(ref string) (sizer.LinguistAttributes, error) {
+	file, _, resp, err := prp.clientWrapper.client.Repositories.GetContents(prp.ctx, prp.repoOwner, prp.repoName, GitAttributesPath, &github.RepositoryContentGetOptions{Ref: ref})
 	if err != nil {
-		return err
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if file == nil {
+		return nil, withKind(ErrConfig, fmt.Errorf("%s is a directory", GitAttributesPath))
 	}
 
-	err = removeOtherSizeLabels(prp.ctx, prp.clientWrapper.client, prp.repoOwner, prp.repoName, prp.prNumber, pr, prp.config, entry)
+	content, err := file.GetContent()
 	if err != nil {
-		return err
+		return nil, err
 	}
+	return sizer.ParseGitAttributes(content), nil
+}
 
-	for _, label := range entry.Labels {
-		labelExists := labelExists(pr, label)
-		if !labelExists {
-			_, _, err = prp.clientWrapper.client.Issues.AddLabelsToIssue(prp.ctx, prp.repoOwner, prp.repoName, prp.prNumber, []string{label})
-			if err != nil {
-				return err
-			}
-		}
+// isFileListTruncated checks if GitHub listed fewer files than the pull request changes.
+func isFileListTruncated(pr *github.PullRequest, files []*github.CommitFile) bool {
+	return pr.GetChangedFiles() > len(files)
+}
+
+// updatePullRequestLabel updates the labels of the pull request to the labels of the size entries and the extra labels,
+// such as path labels. The final set of labels is applied in a single request, keeping the labels that are not size
+// or path labels. Nothing is sent if the pull request already has the right labels.
+func (prp *PullRequestProcessor) updatePullRequestLabel(pr *github.PullRequest, entries []sizer.ConfigEntry, extraLabels []string) error {
+	var labels []string
+	for _, entry := range entries {
+		labels = appendMissing(labels, entry.Labels...)
 	}
-	return nil
+	labels = appendMissing(labels, extraLabels...)
+
+	// Labels added by other workflows since the pull request was fetched must be kept
+	if err := prp.refreshLabels(pr); err != nil {
+		return err
+	}
+	labelsToAdd, labelsToRemove := missingLabels(pr, labels), staleLabels(pr, prp.config, labels)
+	if prp.dryRun {
+		prp.printPlannedLabelChanges(labelsToAdd, labelsToRemove)
+		return nil
+	}
+	if len(labelsToAdd) == 0 && len(labelsToRemove) == 0 {
+		prp.log.Infof("Labels of pull request #%d are up to date, nothing to change", prp.prNumber)
+		return nil
+	}
+
+	_, _, err := prp.clientWrapper.client.Issues.ReplaceLabelsForIssue(prp.ctx, prp.repoOwner, prp.repoName, prp.prNumber, finalLabels(pr, prp.config, labels))
+	return err
 }
 
-// removeOtherSizeLabels removes labels that are different from the current size labels.
-func removeOtherSizeLabels(ctx context.Context, client *github.Client, repoOwner, repoName string, prNumber int, pr *github.PullRequest, config Config, entry ConfigEntry) error {
-	for _, label := range pr.Labels {
-		if isSizeLabel(label.GetName(), config.LabelConfigs) && !contains(entry.Labels, label.GetName()) {
-			_, err := client.Issues.RemoveLabelForIssue(ctx, repoOwner, repoName, prNumber, label.GetName())
-			if err != nil {
-				return err
-			}
+// refreshLabels replaces the labels of the pull request with its current labels, following pagination.
+func (prp *PullRequestProcessor) refreshLabels(pr *github.PullRequest) error {
+	var labels []*github.Label
+	opts := &github.ListOptions{PerPage: LabelsPerPage}
+	for {
+		page, resp, err := prp.clientWrapper.client.Issues.ListLabelsByIssue(prp.ctx, prp.repoOwner, prp.repoName, prp.prNumber, opts)
+		if err != nil {
+			return fmt.Errorf("listing labels of pull request: %w", err)
+		}
+		labels = append(labels, page...)
+		if resp.NextPage == 0 {
+			break
 		}
+		opts.Page = resp.NextPage
 	}
+	pr.Labels = labels
 	return nil
 }
 
-// isSizeLabel checks if a label is a size label.
-func isSizeLabel(labelName string, labelConfigs []ConfigEntry) bool {
-	for _, configLabel := range labelConfigs {
-		if contains(configLabel.Labels, labelName) {
-			return true
-		}
+// printPlannedLabelChanges prints the label changes a dry run would apply to the pull request.
+func (prp *PullRequestProcessor) printPlannedLabelChanges(labelsToAdd, labelsToRemove []string) {
+	if len(labelsToAdd) == 0 && len(labelsToRemove) == 0 {
+		prp.log.Infof("Dry run: labels of pull request #%d are up to date, nothing to change", prp.prNumber)
+		return
+	}
+	for _, label := range labelsToRemove {
+		prp.log.Infof("Dry run: would remove label %q from pull request #%d", label, prp.prNumber)
+	}
+	for _, label := range labelsToAdd {
+		prp.log.Infof("Dry run: would add label %q to pull request #%d", label, prp.prNumber)
 	}
-	return false
 }
 
-// labelExists checks if a label already exists on a pull request.
-func labelExists(pr *github.PullRequest, labelName string) bool {
+// finalLabels returns the labels the pull request should have after applying the given size and path labels.
+// These are its current labels without the stale size and path labels, followed by the missing labels.
+func finalLabels(pr *github.PullRequest, config Config, wanted []string) []string {
+	labels := make([]string, 0, len(pr.Labels)+len(wanted))
 	for _, label := range pr.Labels {
-		if label.GetName() == labelName {
-			return true
+		if !isManagedLabel(label.GetName(), config) || contains(wanted, label.GetName()) {
+			labels = append(labels, label.GetName())
 		}
 	}
-	return false
+	return append(labels, missingLabels(pr, wanted)...)
 }
 
-// calculateSizeAndDiff calculates the size and diff for the pull request.
-func calculateSizeAndDiff(files []*github.CommitFile, config Config) (int, int) {
-	numberOfFiles, numberOfLines := 0, 0
-	for _, file := range files {
-		if config.AddedLinesOnly && file.GetStatus() == "removed" {
-			continue
+// staleLabels returns the size and path labels of the pull request that are not wanted anymore.
+func staleLabels(pr *github.PullRequest, config Config, wanted []string) []string {
+	var labels []string
+	for _, label := range pr.Labels {
+		if isManagedLabel(label.GetName(), config) && !contains(wanted, label.GetName()) {
+			labels = append(labels, label.GetName())
 		}
+	}
+	return labels
+}
 
-		if !shouldExcludeFile(file.GetFilename(), config.ExcludeFiles) {
-			numberOfFiles++
-
-			if config.AddedLinesOnly {
-				numberOfLines += file.GetAdditions()
-			} else {
-				numberOfLines += file.GetChanges()
-			}
+// missingLabels returns the wanted labels that the pull request does not have yet.
+func missingLabels(pr *github.PullRequest, wanted []string) []string {
+	var labels []string
+	for _, label := range wanted {
+		if !labelExists(pr, label) {
+			labels = append(labels, label)
 		}
 	}
-	return numberOfFiles, numberOfLines
+	return labels
 }
 
-func mapNumberOfChangesToSize(numberOfFiles, numberOfLines int, config Config) (ConfigEntry, ConfigEntry) {
-	size := getSize(config.LabelConfigs, numberOfFiles, ParamNameFiles)
-	diff := getSize(config.LabelConfigs, numberOfLines, ParamNameDiff)
-	return size, diff
+// isManagedLabel checks if a label is a size label of the pull request or a scope, or a path label.
+// The action adds and removes these labels.
+func isManagedLabel(labelName string, config Config) bool {
+	return isSizeLabel(labelName, config.allLabelConfigs()) || isPathLabel(labelName, config.PathLabels)
 }
 
-// shouldExcludeFile checks if a file should be excluded based on the configuration.
-func shouldExcludeFile(filename string, patterns []string) bool {
-	for _, pattern := range patterns {
-		// Check against the full path
-		matched, err := filepath.Match(pattern, filename)
-		if err != nil {
-			fmt.Printf("Invalid pattern %s: %s\n", pattern, err)
-			continue
-		}
-		if matched {
-			return true
+// appendMissing appends the labels that are not in the list yet.
+func appendMissing(labels []string, newLabels ...string) []string {
+	for _, label := range newLabels {
+		if !contains(labels, label) {
+			labels = append(labels, label)
 		}
+	}
+	return labels
+}
 
-		// Extract just the file name and check the pattern again
-		justFileName := filepath.Base(filename)
-		matched, err = filepath.Match(pattern, justFileName)
-		if err != nil {
-			fmt.Printf("Invalid pattern %s: %s\n", pattern, err)
-			continue
-		}
-		if matched {
+// isSizeLabel checks if a label is a size label.
+func isSizeLabel(labelName string, labelConfigs []sizer.ConfigEntry) bool {
+	for _, configLabel := range labelConfigs {
+		if contains(configLabel.Labels, labelName) {
 			return true
 		}
-
-		// Check if the pattern specifies a directory and matches the beginning of the filename
-		if strings.HasSuffix(pattern, "/*") {
-			dirPattern := filepath.Dir(pattern)
-			if strings.HasPrefix(filename, dirPattern) {
-				return true
-			}
-		}
 	}
 	return false
 }
 
-// getSize retrieves the size configuration based on the number of files or diffs.
-func getSize(configuration []ConfigEntry, currentCount int, paramName string) ConfigEntry {
-	for _, entry := range configuration {
-		var entryValue int
-		switch paramName {
-		case ParamNameFiles:
-			entryValue = entry.Files
-		case ParamNameDiff:
-			entryValue = entry.Diff
-		}
-
-		if currentCount <= entryValue {
-			return entry
+// labelExists checks if a label already exists on a pull request.
+func labelExists(pr *github.PullRequest, labelName string) bool {
+	for _, label := range pr.Labels {
+		if label.GetName() == labelName {
+			return true
 		}
 	}
-	return configuration[len(configuration)-1]
+	return false
 }
 
-// getBiggestEntry determines the largest entry between two ConfigEntry objects based on the user-defined order.
-func getBiggestEntry(configEntries []ConfigEntry, size, diff ConfigEntry) ConfigEntry {
-	sizeIndex := findConfigEntryIndex(configEntries, size.Size)
-	diffIndex := findConfigEntryIndex(configEntries, diff.Size)
-
-	if sizeIndex >= diffIndex {
-		return size
+// newFileChanges converts the files of a pull request into file changes.
+func newFileChanges(files []*github.CommitFile) []sizer.FileChange {
+	changes := make([]sizer.FileChange, 0, len(files))
+	for _, file := range files {
+		changes = append(changes, sizer.FileChange{
+			Filename:  file.GetFilename(),
+			Status:    file.GetStatus(),
+			Additions: file.GetAdditions(),
+			Deletions: file.GetDeletions(),
+			Changes:   file.GetChanges(),
+		})
 	}
-	return diff
+	return changes
 }
 
-// findConfigEntryIndex finds the index of a ConfigEntry in the configuration based on size.
-func findConfigEntryIndex(entries []ConfigEntry, size string) int {
-	for i, entry := range entries {
-		if entry.Size == size {
-			return i
-		}
+// calculateApproximateSizeAndDiff calculates the size and diff from the pull request totals.
+// It is used when the file list is truncated, so exclusions can not be applied.
+func calculateApproximateSizeAndDiff(pr *github.PullRequest, config Config) (int, int) {
+	if config.AddedLinesOnly {
+		return pr.GetChangedFiles(), pr.GetAdditions()
 	}
-	return -1
+	return pr.GetChangedFiles(), pr.GetAdditions() + pr.GetDeletions()
 }
 
 // parseRepoOwner extracts the repository owner from the full repository name.
@@ -381,12 +729,12 @@ func isValidRepoNameFormat(repoName string) bool {
 	return len(parts) == 2 && parts[0] != "" && parts[1] != ""
 }
 
-// exitOnError terminates the program if an error is encountered.
-func exitOnError(action string, err error) {
+// wrapError adds the action that failed to an error. It returns nil for a nil error.
+func wrapError(action string, err error) error {
 	if err != nil {
-		fmt.Printf("Error %s: %v\n", action, err)
-		os.Exit(1)
+		return fmt.Errorf("%s: %w", action, err)
 	}
+	return nil
 }
 
 // contains checks if a slice of strings contains a given string.
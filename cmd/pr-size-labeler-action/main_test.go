@@ -1,299 +1,106 @@
 package main
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
 	"testing"
 
+	"github.com/cbrgm/pr-size-labeler-action/pkg/sizer"
 	"github.com/google/go-github/v90/github"
 )
 
-func TestMapSizeAndDiff(t *testing.T) {
-	// Define the configuration separately for clarity
-	xsConfig := ConfigEntry{"xs", 10, 1, []string{"size/xs"}}
-	sConfig := ConfigEntry{"s", 50, 10, []string{"size/s"}}
-	mConfig := ConfigEntry{"m", 100, 20, []string{"size/m"}}
-	lConfig := ConfigEntry{"l", 500, 50, []string{"size/l"}}
-	xlConfig := ConfigEntry{"xl", 1000, 100, []string{"size/xl"}}
-
-	config := Config{
-		ExcludeFiles: []string{"exclude.*"},
-		LabelConfigs: []ConfigEntry{xsConfig, sConfig, mConfig, lConfig, xlConfig},
+func TestLoadConfig(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "pull-request-size.yml")
+	data := []byte("exclude_files: [\"*.xyz\"]\nlabel_configs:\n  - size: xs\n    diff: 10\n    files: 1\n    labels: [\"size/xs\"]\n")
+	if err := os.WriteFile(filePath, data, 0o600); err != nil {
+		t.Fatal(err)
 	}
 
-	tests := []struct {
-		name     string
-		files    []*github.CommitFile
-		config   Config
-		wantSize ConfigEntry
-		wantDiff ConfigEntry
-	}{
-		{
-			"No files should result in the smallest size and diff",
-			[]*github.CommitFile{},
-			config,
-			xsConfig,
-			xsConfig,
-		},
-		{
-			"Files within 'small' thresholds",
-			[]*github.CommitFile{
-				mockCommitFile("file1.go", "added", 5, 5),
-				mockCommitFile("file2.go", "modified", 10, 8),
-			},
-			config,
-			sConfig,
-			sConfig,
-		},
-		{
-			"Files exceeding 'small' but within 'medium' thresholds",
-			[]*github.CommitFile{
-				mockCommitFile("file1.go", "modified", 30, 20),
-				mockCommitFile("file2.go", "modified", 70, 60),
-			},
-			config,
-			sConfig,
-			mConfig,
-		},
-		{
-			"Files with one excluded file",
-			[]*github.CommitFile{
-				mockCommitFile("exclude.txt", "modified", 100, 90),
-				mockCommitFile("file2.go", "modified", 20, 10),
-			},
-			config,
-			xsConfig,
-			sConfig,
-		},
+	config, err := loadConfig(filePath, nil)
+	if err != nil {
+		t.Fatalf("loadConfig() error = %v", err)
 	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			numberOfFiles, numberOfLines := calculateSizeAndDiff(tt.files, tt.config)
-			gotSize, gotDiff := mapNumberOfChangesToSize(numberOfFiles, numberOfLines, tt.config)
-			if !configEntriesAreEqual(gotSize, tt.wantSize) || !configEntriesAreEqual(gotDiff, tt.wantDiff) {
-				t.Errorf("calculateSizeAndDiff() = size: %v, diff: %v, want size: %v, want diff: %v", gotSize, gotDiff, tt.wantSize, tt.wantDiff)
-			}
-		})
+	if len(config.ExcludeFiles) != 1 || len(config.LabelConfigs) != 1 || !reflect.DeepEqual(config.LabelConfigs[0], sizer.ConfigEntry{Size: "xs", Diff: 10, Files: 1, Labels: []string{"size/xs"}}) {
+		t.Errorf("loadConfig() = %+v", config)
 	}
-}
 
-func TestCalculateSizeAndDiff(t *testing.T) {
-	configDefault := Config{
-		ExcludeFiles:   []string{"exclude.*"},
-		LabelConfigs:   []ConfigEntry{},
-		AddedLinesOnly: false,
+	_, err = loadConfig(filepath.Join(t.TempDir(), "missing.yml"), nil)
+	if !errors.Is(err, ErrConfig) {
+		t.Errorf("loadConfig() error = %v, want ErrConfig for a missing explicit path", err)
 	}
 
-	configAddedLinesOnly := Config{
-		ExcludeFiles:   []string{"exclude.*"},
-		LabelConfigs:   []ConfigEntry{},
-		AddedLinesOnly: true,
+	t.Chdir(t.TempDir())
+	config, err = loadConfig(DefaultConfigPath, nil)
+	if err != nil {
+		t.Fatalf("loadConfig() error = %v", err)
 	}
-
-	tests := []struct {
-		name              string
-		files             []*github.CommitFile
-		config            Config
-		wantNumberOfFiles int
-		wantNumberOfLines int
-	}{
-		{
-			"No files should return 0 for everything",
-			[]*github.CommitFile{},
-			configDefault,
-			0,
-			0,
-		},
-		{
-			"No files should return 0 for everything with added lines only",
-			[]*github.CommitFile{},
-			configAddedLinesOnly,
-			0,
-			0,
-		},
-		{
-			"Files with an excluded file",
-			[]*github.CommitFile{
-				mockCommitFile("exclude.txt", "modified", 100, 90),
-				mockCommitFile("file2.go", "modified", 20, 10),
-			},
-			configDefault,
-			1,
-			20,
-		},
-		{
-			"Files with an excluded file with added lines only",
-			[]*github.CommitFile{
-				mockCommitFile("exclude.txt", "modified", 100, 90),
-				mockCommitFile("file2.go", "modified", 20, 10),
-			},
-			configAddedLinesOnly,
-			1,
-			10,
-		},
-		{
-			"A deleted file should not count towards the size",
-			[]*github.CommitFile{
-				mockCommitFile("file1.go", "removed", 50, 0),
-				mockCommitFile("file2.go", "modified", 30, 25),
-				mockCommitFile("file3.go", "modified", 40, 35),
-			},
-			configAddedLinesOnly,
-			2,
-			60,
-		},
-		{
-			"A modified file with only deletions should still count towards the number of files",
-			[]*github.CommitFile{
-				mockCommitFile("file2.go", "modified", 30, 0),
-				mockCommitFile("file3.go", "modified", 40, 35),
-			},
-			configAddedLinesOnly,
-			2,
-			35,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			gotNumberOfFiles, gotNumberOfLines := calculateSizeAndDiff(tt.files, tt.config)
-			if gotNumberOfFiles != tt.wantNumberOfFiles || gotNumberOfLines != tt.wantNumberOfLines {
-				t.Errorf("calculateSizeAndDiff() = files: %d, want files: %d, lines: %d, want lines: %d", gotNumberOfFiles, tt.wantNumberOfFiles, gotNumberOfLines, tt.wantNumberOfLines)
-			}
-		})
-	}
-}
-
-func TestIsValidGitHubEventType(t *testing.T) {
-	tests := []struct {
-		name      string
-		eventName string
-		want      bool
-	}{
-		{"Valid Event pull_request", "pull_request", true},
-		{"Valid Event pull_request_target", "pull_request_target", true},
-		{"Invalid Event empty", "", false},
-		{"Invalid Event random string", "random_event", false},
-		{"Invalid Event issue", "issue", false},
-		{"Invalid Event commit", "commit", false},
-		{"Invalid Event push", "push", false},
-		{"Invalid Event merge", "merge", false},
-		{"Invalid Event null", "null", false},
-		{"Invalid Event pull_request_closed", "pull_request_closed", false},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			if got := isValidGitHubEventType(tt.eventName); got != tt.want {
-				t.Errorf("isValidGitHubEventType(%v) = %v, want %v", tt.eventName, got, tt.want)
-			}
-		})
+	if !reflect.DeepEqual(config, DefaultConfig()) {
+		t.Errorf("loadConfig() = %+v, want the default configuration", config)
 	}
 }
 
-func TestGetSize(t *testing.T) {
-	// Define configuration entries for clarity
-	xsConfig := ConfigEntry{"xs", 10, 1, []string{"size/xs"}}
-	sConfig := ConfigEntry{"s", 50, 10, []string{"size/s"}}
-	mConfig := ConfigEntry{"m", 100, 20, []string{"size/m"}}
-	lConfig := ConfigEntry{"l", 500, 50, []string{"size/l"}}
-	xlConfig := ConfigEntry{"xl", 1000, 100, []string{"size/xl"}}
-
-	configuration := []ConfigEntry{xsConfig, sConfig, mConfig, lConfig, xlConfig}
-
+func TestParseConfig(t *testing.T) {
 	tests := []struct {
-		name          string
-		configuration []ConfigEntry
-		currentCount  int
-		paramName     string
-		want          ConfigEntry
+		name    string
+		data    string
+		wantErr error
 	}{
-		// Tests for file count
-		{"Fewer files than XS threshold", configuration, 0, ParamNameFiles, xsConfig},
-		{"Files equal to S threshold", configuration, 10, ParamNameFiles, sConfig},
-		{"Files between S and M thresholds", configuration, 15, ParamNameFiles, mConfig},
-		{"More files than XL threshold", configuration, 105, ParamNameFiles, xlConfig},
-
-		// Tests for diff count
-		{"Fewer changes than XS threshold", configuration, 5, ParamNameDiff, xsConfig},
-		{"Changes equal to M threshold", configuration, 100, ParamNameDiff, mConfig},
-		{"Changes between S and M thresholds", configuration, 35, ParamNameDiff, sConfig},
-		{"More changes than XL threshold", configuration, 1500, ParamNameDiff, xlConfig},
+		{"ValidConfig", "label_configs:\n  - size: xs\n    diff: 10\n    files: 1\n    labels: [\"size/xs\"]\n", nil},
+		{"EmptyFile", "", ErrNoLabelConfigs},
+		{"EmptyLadder", "exclude_files: [\"*.xyz\"]\nlabel_configs: []\n", ErrNoLabelConfigs},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := getSize(tt.configuration, tt.currentCount, tt.paramName)
-			if !configEntriesAreEqual(got, tt.want) {
-				t.Errorf("getSize() = %v, want %v", got, tt.want)
+			if _, err := parseConfig([]byte(tt.data), nil); !errors.Is(err, tt.wantErr) {
+				t.Errorf("parseConfig() error = %v, want %v", err, tt.wantErr)
 			}
 		})
 	}
 }
 
-func TestGetBiggestEntry(t *testing.T) {
-	entries := []ConfigEntry{
-		{"small", 10, 1, []string{"label1"}},
-		{"medium", 20, 2, []string{"label2"}},
-		{"large", 30, 3, []string{"label3"}},
+func TestDefaultConfig(t *testing.T) {
+	config := DefaultConfig()
+	if len(config.LabelConfigs) != 5 {
+		t.Fatalf("DefaultConfig() has %d entries, want 5", len(config.LabelConfigs))
 	}
-
-	tests := []struct {
-		name    string
-		entries []ConfigEntry
-		size    ConfigEntry
-		diff    ConfigEntry
-		want    ConfigEntry
-	}{
-		{"SizeLarger", entries, entries[2], entries[1], entries[2]},
-		{"DiffLarger", entries, entries[0], entries[2], entries[2]},
-		{"EqualSizeDiff", entries, entries[1], entries[1], entries[1]},
-		{"SizeNotInConfig", entries, ConfigEntry{"xlarge", 40, 4, []string{"label4"}}, entries[1], entries[1]},
-		{"DiffNotInConfig", entries, entries[1], ConfigEntry{"xlarge", 40, 4, []string{"label4"}}, entries[1]},
-		{
-			"BothNotInConfig", entries,
-			ConfigEntry{"xlarge", 40, 4, []string{"label4"}},
-			ConfigEntry{"xxlarge", 50, 5, []string{"label5"}},
-			ConfigEntry{"xlarge", 40, 4, []string{"label4"}},
-		}, // Expecting `size` to be returned
-		{"SingleEntryConfig", []ConfigEntry{{"single", 10, 1, []string{"label1"}}}, ConfigEntry{"single", 10, 1, []string{"label1"}}, ConfigEntry{"single", 10, 1, []string{"label1"}}, ConfigEntry{"single", 10, 1, []string{"label1"}}},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			got := getBiggestEntry(tt.entries, tt.size, tt.diff)
-			if !configEntriesAreEqual(got, tt.want) {
-				t.Errorf("getBiggestEntry() = %v, want %v", got, tt.want)
-			}
-		})
+	if got := sizer.GetSize(config.LabelConfigs, 100000, sizer.ParamNameDiff); got.Size != "xl" {
+		t.Errorf("GetSize() = %v, want xl", got.Size)
 	}
 }
 
-func TestFindConfigEntryIndex(t *testing.T) {
-	entries := []ConfigEntry{
-		{"small", 10, 1, []string{"label1"}},
-		{"medium", 20, 2, []string{"label2"}},
-		{"large", 30, 3, []string{"label3"}},
-	}
-
+func TestIsValidGitHubEventType(t *testing.T) {
 	tests := []struct {
-		name    string
-		entries []ConfigEntry
-		size    string
-		want    int
+		name      string
+		eventName string
+		prNumber  string
+		want      bool
 	}{
-		{"SizeAtBeginning", entries, "small", 0},
-		{"SizeAtEnd", entries, "large", 2},
-		{"SizeInMiddle", entries, "medium", 1},
-		{"SizeNotExists", entries, "extra-large", -1},
-		{"SingleEntryMatch", []ConfigEntry{{"single", 10, 1, []string{"label1"}}}, "single", 0},
-		{"SingleEntryNoMatch", []ConfigEntry{{"single", 10, 1, []string{"label1"}}}, "double", -1},
-		{"EmptyList", []ConfigEntry{}, "any", -1},
+		{"Valid Event pull_request", "pull_request", "1", true},
+		{"Valid Event pull_request_target", "pull_request_target", "1", true},
+		{"Valid Event workflow_run", "workflow_run", "1", true},
+		{"Valid Event issue_comment", "issue_comment", "1", true},
+		{"Valid Event pull_request_review", "pull_request_review", "1", true},
+		{"Valid Event merge_group", "merge_group", "1", true},
+		{"Valid Event workflow_dispatch with pull request", "workflow_dispatch", "1", true},
+		{"Valid Event schedule", "schedule", "", true},
+		{"Valid Event workflow_dispatch", "workflow_dispatch", "", true},
+		{"Invalid Event empty", "", "", false},
+		{"Invalid Event random string", "random_event", "", false},
+		{"Invalid Event push", "push", "", false},
+		{"Invalid Event pull_request without pull request", "pull_request", "", false},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := findConfigEntryIndex(tt.entries, tt.size); got != tt.want {
-				t.Errorf("findConfigEntryIndex() = %v, want %v", got, tt.want)
+			if got := isValidGitHubEventType(tt.eventName, tt.prNumber); got != tt.want {
+				t.Errorf("isValidGitHubEventType(%v, %v) = %v, want %v", tt.eventName, tt.prNumber, got, tt.want)
 			}
 		})
 	}
@@ -385,21 +192,6 @@ func TestContains(t *testing.T) {
 	}
 }
 
-func configEntriesAreEqual(a, b ConfigEntry) bool {
-	if a.Size != b.Size || a.Diff != b.Diff || a.Files != b.Files {
-		return false
-	}
-	if len(a.Labels) != len(b.Labels) {
-		return false
-	}
-	for i, label := range a.Labels {
-		if label != b.Labels[i] {
-			return false
-		}
-	}
-	return true
-}
-
 func mockCommitFile(filename string, status string, changes int, additions int) *github.CommitFile {
 	return &github.CommitFile{
 		Filename:  &filename,
@@ -410,9 +202,9 @@ func mockCommitFile(filename string, status string, changes int, additions int)
 }
 
 func TestIsSizeLabel(t *testing.T) {
-	labelConfigs := []ConfigEntry{
-		{"xs", 10, 1, []string{"size/xs", "review-wanted"}},
-		{"s", 50, 10, []string{"size/s", "review-wanted"}},
+	labelConfigs := []sizer.ConfigEntry{
+		{Size: "xs", Diff: 10, Files: 1, Labels: []string{"size/xs", "review-wanted"}},
+		{Size: "s", Diff: 50, Files: 10, Labels: []string{"size/s", "review-wanted"}},
 		// Add more ConfigEntry if needed
 	}
 
@@ -444,6 +236,89 @@ func mockPullRequest(labels ...string) *github.PullRequest {
 	return &github.PullRequest{Labels: githubLabels}
 }
 
+func TestStaleLabels(t *testing.T) {
+	config := Config{Config: sizer.Config{LabelConfigs: []sizer.ConfigEntry{
+		{Size: "xs", Diff: 10, Files: 1, Labels: []string{"size/xs"}},
+		{Size: "s", Diff: 50, Files: 10, Labels: []string{"size/s", "review-wanted"}},
+		{Size: "m", Diff: 100, Files: 20, Labels: []string{"size/m", "review-wanted"}},
+	}}, PathLabels: []PathLabel{{Label: "area/frontend", Paths: []string{"web/**"}}}}
+
+	tests := []struct {
+		name  string
+		pr    *github.PullRequest
+		entry sizer.ConfigEntry
+		want  []string
+	}{
+		{"StaleSizeLabel", mockPullRequest("size/xs", "bug"), config.LabelConfigs[1], []string{"size/xs"}},
+		{"SharedLabelIsKept", mockPullRequest("size/s", "review-wanted"), config.LabelConfigs[2], []string{"size/s"}},
+		{"UpToDate", mockPullRequest("size/m", "review-wanted"), config.LabelConfigs[2], nil},
+		{"NoLabels", mockPullRequest(), config.LabelConfigs[0], nil},
+		{"StalePathLabel", mockPullRequest("size/xs", "area/frontend"), config.LabelConfigs[0], []string{"area/frontend"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := staleLabels(tt.pr, config, tt.entry.Labels); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("staleLabels() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFinalLabels(t *testing.T) {
+	config := Config{Config: sizer.Config{LabelConfigs: []sizer.ConfigEntry{
+		{Size: "xs", Diff: 10, Files: 1, Labels: []string{"size/xs"}},
+		{Size: "s", Diff: 50, Files: 10, Labels: []string{"size/s", "review-wanted"}},
+		{Size: "m", Diff: 100, Files: 20, Labels: []string{"size/m", "review-wanted"}},
+	}}, PathLabels: []PathLabel{
+		{Label: "area/frontend", Paths: []string{"web/**"}},
+		{Label: "needs-db-review", Paths: []string{"db/migrations/**"}},
+	}}
+
+	tests := []struct {
+		name   string
+		pr     *github.PullRequest
+		wanted []string
+		want   []string
+	}{
+		{"ReplacesStaleSizeLabel", mockPullRequest("bug", "size/xs"), config.LabelConfigs[1].Labels, []string{"bug", "size/s", "review-wanted"}},
+		{"KeepsSharedLabel", mockPullRequest("size/s", "review-wanted", "bug"), config.LabelConfigs[2].Labels, []string{"review-wanted", "bug", "size/m"}},
+		{"UpToDate", mockPullRequest("size/m", "review-wanted"), config.LabelConfigs[2].Labels, []string{"size/m", "review-wanted"}},
+		{"NoLabels", mockPullRequest(), config.LabelConfigs[0].Labels, []string{"size/xs"}},
+		{"ReplacesStalePathLabel", mockPullRequest("size/xs", "area/frontend"), []string{"size/xs", "needs-db-review"}, []string{"size/xs", "needs-db-review"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := finalLabels(tt.pr, config, tt.wanted); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("finalLabels() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMissingLabels(t *testing.T) {
+	entry := sizer.ConfigEntry{Size: "m", Diff: 100, Files: 20, Labels: []string{"size/m", "review-wanted"}}
+
+	tests := []struct {
+		name string
+		pr   *github.PullRequest
+		want []string
+	}{
+		{"AllMissing", mockPullRequest("bug"), []string{"size/m", "review-wanted"}},
+		{"OneMissing", mockPullRequest("review-wanted"), []string{"size/m"}},
+		{"NoneMissing", mockPullRequest("size/m", "review-wanted"), nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := missingLabels(tt.pr, entry.Labels); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("missingLabels() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestLabelExists(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -465,111 +340,132 @@ func TestLabelExists(t *testing.T) {
 	}
 }
 
-func TestShouldExcludeFile(t *testing.T) {
+func TestIsFileListTruncated(t *testing.T) {
 	tests := []struct {
-		name       string
-		filename   string
-		patterns   []string
-		wantResult bool
+		name  string
+		pr    *github.PullRequest
+		files []*github.CommitFile
+		want  bool
 	}{
-		{
-			name:       "exclude specific file",
-			filename:   "foo.bar",
-			patterns:   []string{"foo.bar"},
-			wantResult: true,
-		},
-		{
-			name:       "exclude by extension",
-			filename:   "example.xyz",
-			patterns:   []string{"*.xyz"},
-			wantResult: true,
-		},
-		{
-			name:       "do not exclude unrelated file",
-			filename:   "test.txt",
-			patterns:   []string{"*.xyz"},
-			wantResult: false,
-		},
-		{
-			name:       "exclude with full path",
-			filename:   "/path/to/file/foo.bar",
-			patterns:   []string{"/path/to/file/foo.bar"},
-			wantResult: true,
-		},
-		{
-			name:       "exclude with wildcard in path",
-			filename:   "/some/path/example.txt",
-			patterns:   []string{"/some/path/*"},
-			wantResult: true,
-		},
-		{
-			name:       "do not exclude when path pattern does not match",
-			filename:   "/another/path/example.txt",
-			patterns:   []string{"/some/path/*"},
-			wantResult: false,
-		},
-		{
-			name:       "exclude with complex path pattern",
-			filename:   "/complex/path/with/multiple/sections/file.xyz",
-			patterns:   []string{"/complex/path/*/multiple/*/file.xyz"},
-			wantResult: true,
-		},
-		{
-			name:       "exclude nested directory file",
-			filename:   "/nested/directory/structure/file.txt",
-			patterns:   []string{"/nested/directory/*"},
-			wantResult: true,
-		},
-		{
-			name:       "exclude using multiple patterns",
-			filename:   "multiple.patterns.match",
-			patterns:   []string{"*.patterns.*", "multiple.*"},
-			wantResult: true,
-		},
-		{
-			name:       "do not exclude when multiple patterns do not match",
-			filename:   "no.pattern.match",
-			patterns:   []string{"*.patterns.*", "multiple.*"},
-			wantResult: false,
-		},
-		{
-			name:       "exclude using complex wildcard patterns",
-			filename:   "/var/log/app.log",
-			patterns:   []string{"/var/*/app.*", "*.log"},
-			wantResult: true,
-		},
-		{
-			name:       "exclude file in root directory",
-			filename:   "/rootfile.txt",
-			patterns:   []string{"/*.txt"},
-			wantResult: true,
-		},
-		{
-			name:       "do not exclude file in non-root directory with root pattern",
-			filename:   "/dir/rootfile.txt",
-			patterns:   []string{"/*.txt"},
-			wantResult: false,
-		},
-		{
-			name:       "exclude using pattern with escaped special characters",
-			filename:   "file_with_underscores_and_numbers_123.txt",
-			patterns:   []string{"file_with_underscores_and_numbers_*.txt"},
-			wantResult: true,
-		},
-		{
-			name:       "exclude using pattern with question mark",
-			filename:   "file?.txt",
-			patterns:   []string{"file?.txt"},
-			wantResult: true,
-		},
+		{"AllFilesListed", &github.PullRequest{ChangedFiles: github.Ptr(2)}, []*github.CommitFile{mockCommitFile("a.go", "added", 1, 1), mockCommitFile("b.go", "added", 1, 1)}, false},
+		{"FilesMissing", &github.PullRequest{ChangedFiles: github.Ptr(3500)}, []*github.CommitFile{mockCommitFile("a.go", "added", 1, 1)}, true},
+		{"NoChangedFiles", &github.PullRequest{}, []*github.CommitFile{}, false},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := shouldExcludeFile(tt.filename, tt.patterns)
-			if result != tt.wantResult {
-				t.Errorf("shouldExcludeFile(%v, %v) = %v, want %v", tt.filename, tt.patterns, result, tt.wantResult)
+			if got := isFileListTruncated(tt.pr, tt.files); got != tt.want {
+				t.Errorf("isFileListTruncated() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
+
+func TestCalculateApproximateSizeAndDiff(t *testing.T) {
+	pr := &github.PullRequest{
+		ChangedFiles: github.Ptr(3500),
+		Additions:    github.Ptr(12000),
+		Deletions:    github.Ptr(8000),
+	}
+
+	tests := []struct {
+		name              string
+		config            Config
+		wantNumberOfFiles int
+		wantNumberOfLines int
+	}{
+		{"AllLines", Config{}, 3500, 20000},
+		{"AddedLinesOnly", Config{Config: sizer.Config{AddedLinesOnly: true}}, 3500, 12000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotNumberOfFiles, gotNumberOfLines := calculateApproximateSizeAndDiff(pr, tt.config)
+			if gotNumberOfFiles != tt.wantNumberOfFiles || gotNumberOfLines != tt.wantNumberOfLines {
+				t.Errorf("calculateApproximateSizeAndDiff() = files: %d, want files: %d, lines: %d, want lines: %d", gotNumberOfFiles, tt.wantNumberOfFiles, gotNumberOfLines, tt.wantNumberOfLines)
+			}
+		})
+	}
+}
+
+func TestNewFileChanges(t *testing.T) {
+	files := []*github.CommitFile{
+		mockCommitFile("main.go", "modified", 20, 10),
+		mockCommitFile("old.go", "removed", 5, 0),
+	}
+
+	want := []sizer.FileChange{
+		{Filename: "main.go", Status: "modified", Additions: 10, Changes: 20},
+		{Filename: "old.go", Status: "removed", Changes: 5},
+	}
+	if got := newFileChanges(files); !reflect.DeepEqual(got, want) {
+		t.Errorf("newFileChanges() = %+v, want %+v", got, want)
+	}
+}
+
+func TestUpdatePullRequestLabelKeepsLabelsAddedMeanwhile(t *testing.T) {
+	config := Config{Config: sizer.Config{LabelConfigs: []sizer.ConfigEntry{
+		{Size: "xs", Diff: 10, Files: 1, Labels: []string{"size/xs"}},
+		{Size: "s", Diff: 50, Files: 10, Labels: []string{"size/s"}},
+	}}}
+
+	var replaced []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			// Another workflow added a label after the pull request was fetched
+			_ = json.NewEncoder(w).Encode([]map[string]string{{"name": "size/xs"}, {"name": "bug"}, {"name": "area/api"}})
+		case http.MethodPut:
+			_ = json.NewDecoder(r.Body).Decode(&replaced)
+			_ = json.NewEncoder(w).Encode([]map[string]string{})
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	serverURL := server.URL + "/"
+	client, err := github.NewClient(github.WithURLs(&serverURL, &serverURL))
+	if err != nil {
+		t.Fatalf("creating client: %v", err)
+	}
+
+	prp := NewPullRequestProcessor(t.Context(), &GitHubClientWrapper{client: client}, "owner", "repo", 1, config, false)
+	if err := prp.updatePullRequestLabel(mockPullRequest("size/xs", "bug"), config.LabelConfigs[1:], nil); err != nil {
+		t.Fatalf("updatePullRequestLabel() error = %v", err)
+	}
+	if want := []string{"bug", "area/api", "size/s"}; !reflect.DeepEqual(replaced, want) {
+		t.Errorf("updatePullRequestLabel() replaced labels with %v, want %v", replaced, want)
+	}
+}
+
+func TestPrintPlannedLabelChanges(t *testing.T) {
+	var buf bytes.Buffer
+	prp := NewPullRequestProcessor(t.Context(), nil, "owner", "repo", 7, Config{}, true)
+	prp.log = NewLogger(&buf, false)
+
+	prp.printPlannedLabelChanges([]string{"size/m"}, []string{"size/s"})
+	want := "Dry run: would remove label \"size/s\" from pull request #7\nDry run: would add label \"size/m\" to pull request #7\n"
+	if got := buf.String(); got != want {
+		t.Errorf("printPlannedLabelChanges() wrote %q, want %q", got, want)
+	}
+}
+
+func TestFetchGitAttributesDirectory(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		// The contents API lists the entries of a directory instead of returning a file
+		_ = json.NewEncoder(w).Encode([]map[string]string{{"type": "file", "name": "a"}})
+	}))
+	defer server.Close()
+
+	serverURL := server.URL + "/"
+	client, err := github.NewClient(github.WithURLs(&serverURL, &serverURL))
+	if err != nil {
+		t.Fatalf("creating client: %v", err)
+	}
+
+	prp := NewPullRequestProcessor(t.Context(), &GitHubClientWrapper{client: client}, "owner", "repo", 1, Config{}, false)
+	if _, err := prp.fetchGitAttributes("main"); !errors.Is(err, ErrConfig) {
+		t.Errorf("fetchGitAttributes() error = %v, want %v", err, ErrConfig)
+	}
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/cbrgm/pr-size-labeler-action/pkg/sizer"
+)
+
+// Environment variables pointing to the files GitHub Actions reads step outputs and the job summary from.
+const (
+	EnvGitHubOutput      = "GITHUB_OUTPUT"
+	EnvGitHubStepSummary = "GITHUB_STEP_SUMMARY"
+)
+
+// writeStepOutputs appends the size outputs to $GITHUB_OUTPUT and the size report to $GITHUB_STEP_SUMMARY.
+// Files that are not set, for example when running outside of GitHub Actions, are skipped.
+func writeStepOutputs(result sizer.Result, labelConfigs []sizer.ConfigEntry) error {
+	if err := appendToEnvFile(EnvGitHubOutput, func(w io.Writer) error {
+		delimiter, err := newOutputDelimiter()
+		if err != nil {
+			return err
+		}
+		return writeOutputs(w, result, delimiter)
+	}); err != nil {
+		return err
+	}
+	return appendToEnvFile(EnvGitHubStepSummary, func(w io.Writer) error {
+		_, err := io.WriteString(w, renderSizeReport(result, labelConfigs))
+		return err
+	})
+}
+
+// appendToEnvFile opens the file named by the environment variable for appending and passes it to write.
+func appendToEnvFile(envName string, write func(io.Writer) error) error {
+	filePath := os.Getenv(envName)
+	if filePath == "" {
+		return nil
+	}
+
+	file, err := os.OpenFile(filePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("opening %s: %w", envName, err)
+	}
+	if err := write(file); err != nil {
+		file.Close()
+		return fmt.Errorf("writing %s: %w", envName, err)
+	}
+	return file.Close()
+}
+
+// newOutputDelimiter returns a random delimiter for multiline values in $GITHUB_OUTPUT.
+func newOutputDelimiter() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "ghadelimiter_" + hex.EncodeToString(b), nil
+}
+
+// writeOutputs writes the size outputs in the multiline name<<delimiter format of $GITHUB_OUTPUT.
+// Unlike the name=value format, values such as file names containing newlines can not inject other outputs.
+func writeOutputs(w io.Writer, result sizer.Result, delimiter string) error {
+	excludedFiles, err := json.Marshal(result.ExcludedFiles)
+	if err != nil {
+		return err
+	}
+	if result.ExcludedFiles == nil {
+		excludedFiles = []byte("[]")
+	}
+
+	outputs := []struct {
+		name  string
+		value string
+	}{
+		{"size", result.Entry.Size},
+		{"labels", strings.Join(result.Entry.Labels, ",")},
+		{"diff", strconv.Itoa(result.Lines)},
+		{"files", strconv.Itoa(result.Files)},
+		{"excluded_files", string(excludedFiles)},
+	}
+
+	for _, output := range outputs {
+		if strings.Contains(output.value, delimiter) {
+			return fmt.Errorf("output %s contains the delimiter %s", output.name, delimiter)
+		}
+		if _, err := fmt.Fprintf(w, "%s<<%s\n%s\n%s\n", output.name, delimiter, output.value, delimiter); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cbrgm/pr-size-labeler-action/pkg/sizer"
+)
+
+func TestWriteOutputs(t *testing.T) {
+	tests := []struct {
+		name          string
+		excludedFiles []string
+		want          string
+	}{
+		{
+			"ExcludedFiles",
+			[]string{"go.sum", "vendor/lib.go"},
+			"size<<EOF\nm\nEOF\nlabels<<EOF\nsize/m,pairing-wanted\nEOF\ndiff<<EOF\n812\nEOF\nfiles<<EOF\n14\nEOF\n" +
+				"excluded_files<<EOF\n[\"go.sum\",\"vendor/lib.go\"]\nEOF\n",
+		},
+		{
+			"NoExcludedFiles",
+			nil,
+			"size<<EOF\nm\nEOF\nlabels<<EOF\nsize/m,pairing-wanted\nEOF\ndiff<<EOF\n812\nEOF\nfiles<<EOF\n14\nEOF\n" +
+				"excluded_files<<EOF\n[]\nEOF\n",
+		},
+		{
+			"NewlineInFileName",
+			[]string{"a\nsize=xs"},
+			"size<<EOF\nm\nEOF\nlabels<<EOF\nsize/m,pairing-wanted\nEOF\ndiff<<EOF\n812\nEOF\nfiles<<EOF\n14\nEOF\n" +
+				"excluded_files<<EOF\n[\"a\\nsize=xs\"]\nEOF\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := sizer.Result{
+				Counts:        sizer.Counts{Files: 14, Lines: 812},
+				ExcludedFiles: tt.excludedFiles,
+				Entry:         reportLabelConfigs[2],
+			}
+
+			var sb strings.Builder
+			if err := writeOutputs(&sb, result, "EOF"); err != nil {
+				t.Fatalf("writeOutputs() error = %v", err)
+			}
+			if got := sb.String(); got != tt.want {
+				t.Errorf("writeOutputs() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteOutputsContainingDelimiter(t *testing.T) {
+	result := sizer.Result{ExcludedFiles: []string{"EOF"}, Entry: reportLabelConfigs[2]}
+
+	var sb strings.Builder
+	if err := writeOutputs(&sb, result, "EOF"); err == nil {
+		t.Errorf("writeOutputs() error = nil, want an error for a value containing the delimiter")
+	}
+}
+
+func TestWriteStepOutputs(t *testing.T) {
+	dir := t.TempDir()
+	outputPath := filepath.Join(dir, "output")
+	summaryPath := filepath.Join(dir, "summary")
+	t.Setenv(EnvGitHubOutput, outputPath)
+	t.Setenv(EnvGitHubStepSummary, summaryPath)
+
+	if err := os.WriteFile(outputPath, []byte("previous=value\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	result := sizer.Result{Counts: sizer.Counts{Files: 1, Lines: 5}, FilesEntry: reportLabelConfigs[0], DiffEntry: reportLabelConfigs[0], Entry: reportLabelConfigs[0]}
+	if err := writeStepOutputs(result, reportLabelConfigs); err != nil {
+		t.Fatalf("writeStepOutputs() error = %v", err)
+	}
+
+	output, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(output), "previous=value\nsize<<ghadelimiter_") || !strings.Contains(string(output), "\nxs\nghadelimiter_") {
+		t.Errorf("$GITHUB_OUTPUT = %q, want the outputs appended", output)
+	}
+
+	summary, err := os.ReadFile(summaryPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(summary), "| Lines | 5 | `xs` | - |") {
+		t.Errorf("$GITHUB_STEP_SUMMARY = %q, want the size report", summary)
+	}
+}
+
+func TestWriteStepOutputsWithoutEnv(t *testing.T) {
+	t.Setenv(EnvGitHubOutput, "")
+	t.Setenv(EnvGitHubStepSummary, "")
+
+	if err := writeStepOutputs(sizer.Result{}, reportLabelConfigs); err != nil {
+		t.Errorf("writeStepOutputs() error = %v", err)
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"github.com/cbrgm/pr-size-labeler-action/pkg/sizer"
+	"github.com/google/go-github/v90/github"
+)
+
+// PathLabel adds a label to pull requests changing files matching its paths, such as "area/frontend".
+type PathLabel struct {
+	Label string `yaml:"label"`
+	// Paths are patterns like exclude_files, a later pattern prefixed with '!' excludes files matched by an earlier one.
+	Paths []string `yaml:"paths"`
+	// MinLines is the number of changed lines in matching files the label requires. By default, any change adds it.
+	MinLines int `yaml:"min_lines"`
+}
+
+// Matches checks if the changed files match the paths and change at least MinLines lines in them.
+func (p PathLabel) Matches(files []sizer.FileChange) bool {
+	matched, lines := false, 0
+	for _, file := range files {
+		if sizer.MatchesPatterns(file.Filename, p.Paths) {
+			matched = true
+			lines += file.Changes
+		}
+	}
+	return matched && lines >= p.MinLines
+}
+
+// matchingPathLabels returns the labels of the path labels matching the changed files.
+func matchingPathLabels(pathLabels []PathLabel, files []sizer.FileChange) []string {
+	var labels []string
+	for _, pathLabel := range pathLabels {
+		if pathLabel.Matches(files) {
+			labels = appendMissing(labels, pathLabel.Label)
+		}
+	}
+	return labels
+}
+
+// pathLabels returns the path labels of the pull request.
+// If GitHub did not list all files, the path labels the pull request already has are kept,
+// as they may match files that were not listed.
+func (prp *PullRequestProcessor) pathLabels(pr *github.PullRequest, files []*github.CommitFile) []string {
+	labels := matchingPathLabels(prp.config.PathLabels, newFileChanges(files))
+	if !isFileListTruncated(pr, files) {
+		return labels
+	}
+
+	for _, label := range pr.Labels {
+		if isPathLabel(label.GetName(), prp.config.PathLabels) {
+			labels = appendMissing(labels, label.GetName())
+		}
+	}
+	return labels
+}
+
+// isPathLabel checks if a label is a path label.
+func isPathLabel(labelName string, pathLabels []PathLabel) bool {
+	for _, pathLabel := range pathLabels {
+		if pathLabel.Label == labelName {
+			return true
+		}
+	}
+	return false
+}
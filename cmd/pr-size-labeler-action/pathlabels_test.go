@@ -0,0 +1,67 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/cbrgm/pr-size-labeler-action/pkg/sizer"
+	"github.com/google/go-github/v90/github"
+)
+
+var testPathLabels = []PathLabel{
+	{Label: "area/frontend", Paths: []string{"web/**", "!web/vendor/**"}},
+	{Label: "needs-db-review", Paths: []string{"db/migrations/**"}},
+	{Label: "area/docs", Paths: []string{"*.md", "docs/**"}, MinLines: 50},
+}
+
+func TestMatchingPathLabels(t *testing.T) {
+	tests := []struct {
+		name  string
+		files []sizer.FileChange
+		want  []string
+	}{
+		{"NoMatch", []sizer.FileChange{{Filename: "main.go", Changes: 10}}, nil},
+		{"Frontend", []sizer.FileChange{{Filename: "web/src/app.ts", Changes: 1}}, []string{"area/frontend"}},
+		{"NegatedPath", []sizer.FileChange{{Filename: "web/vendor/lib.js", Changes: 100}}, nil},
+		{"RenameWithoutLines", []sizer.FileChange{{Filename: "db/migrations/002.sql", Status: "renamed"}}, []string{"needs-db-review"}},
+		{"BelowMinLines", []sizer.FileChange{{Filename: "README.md", Changes: 20}, {Filename: "docs/guide.md", Changes: 20}}, nil},
+		{"MinLinesAcrossFiles", []sizer.FileChange{{Filename: "README.md", Changes: 30}, {Filename: "docs/guide.md", Changes: 20}}, []string{"area/docs"}},
+		{
+			"SeveralAreas",
+			[]sizer.FileChange{{Filename: "db/migrations/001.sql", Changes: 5}, {Filename: "web/index.html", Changes: 5}},
+			[]string{"area/frontend", "needs-db-review"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matchingPathLabels(testPathLabels, tt.files); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("matchingPathLabels() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPullRequestProcessorPathLabels(t *testing.T) {
+	prp := &PullRequestProcessor{config: Config{PathLabels: testPathLabels}}
+	files := []*github.CommitFile{mockCommitFile("web/index.html", "modified", 5, 5)}
+
+	tests := []struct {
+		name         string
+		changedFiles int
+		want         []string
+	}{
+		{"AllFilesListed", 1, []string{"area/frontend"}},
+		{"KeepsPathLabelsIfTruncated", MaxPullRequestFiles + 1, []string{"area/frontend", "needs-db-review"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pr := mockPullRequest("needs-db-review", "bug")
+			pr.ChangedFiles = &tt.changedFiles
+			if got := prp.pathLabels(pr, files); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("pathLabels() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cbrgm/pr-size-labeler-action/pkg/sizer"
+)
+
+// MaxReportedExcludedFiles limits the excluded files listed in reports, so comments stay below GitHub's size limit.
+const MaxReportedExcludedFiles = 50
+
+// paramTitles are the names of the dimensions shown in reports.
+var paramTitles = map[string]string{
+	sizer.ParamNameFiles:       "Files",
+	sizer.ParamNameDiff:        "Lines",
+	sizer.ParamNameCommits:     "Commits",
+	sizer.ParamNameDirectories: "Directories",
+	sizer.ParamNameDeletions:   "Deletions",
+	sizer.ParamNameRenames:     "Renames",
+}
+
+// reportedParams returns the dimensions to report: files, diff and the optional dimensions limited by any size.
+func reportedParams(labelConfigs []sizer.ConfigEntry) []string {
+	var params []string
+	for _, paramName := range sizer.ParamNames {
+		if paramName == sizer.ParamNameFiles || paramName == sizer.ParamNameDiff || sizer.IsParamConfigured(labelConfigs, paramName) {
+			params = append(params, paramName)
+		}
+	}
+	return params
+}
+
+// renderSizeReport renders the size result as markdown.
+func renderSizeReport(result sizer.Result, labelConfigs []sizer.ConfigEntry) string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "### Pull request size: `%s`\n\n", result.Entry.Size)
+	if len(result.Entry.Labels) > 0 {
+		fmt.Fprintf(&sb, "Labels: `%s`\n\n", strings.Join(result.Entry.Labels, "`, `"))
+	}
+
+	sb.WriteString("| | Counted | Size | Threshold crossed |\n")
+	sb.WriteString("|---|---:|---|---:|\n")
+	for _, paramName := range reportedParams(labelConfigs) {
+		writeReportRow(&sb, paramTitles[paramName], result.Count(paramName), result.EntryFor(labelConfigs, paramName), labelConfigs, paramName)
+	}
+	fmt.Fprintf(&sb, "\nThe size was decided by %s.\n", result.DecidedBy(labelConfigs))
+	writeScopeReport(&sb, result.Scopes)
+
+	if result.Approximate {
+		fmt.Fprintf(&sb, "\n> [!NOTE]\n> GitHub lists at most %d files, so the size is based on the pull request totals and is approximate.\n", MaxPullRequestFiles)
+	}
+
+	if len(result.ExcludedFiles) > 0 {
+		fmt.Fprintf(&sb, "\n<details>\n<summary>%d excluded files</summary>\n\n", len(result.ExcludedFiles))
+		for _, filename := range result.ExcludedFiles[:min(len(result.ExcludedFiles), MaxReportedExcludedFiles)] {
+			fmt.Fprintf(&sb, "- `%s`\n", filename)
+		}
+		if more := len(result.ExcludedFiles) - MaxReportedExcludedFiles; more > 0 {
+			fmt.Fprintf(&sb, "- …and %d more\n", more)
+		}
+		sb.WriteString("\n</details>\n")
+	}
+	return sb.String()
+}
+
+// writeReportRow writes a single table row of the size result.
+func writeReportRow(sb *strings.Builder, name string, count int, entry sizer.ConfigEntry, labelConfigs []sizer.ConfigEntry, paramName string) {
+	threshold := "-"
+	if value, ok := sizer.CrossedThreshold(labelConfigs, entry, paramName); ok {
+		threshold = fmt.Sprintf("> %d", value)
+	}
+	fmt.Fprintf(sb, "| %s | %d | `%s` | %s |\n", name, count, entry.Size, threshold)
+}
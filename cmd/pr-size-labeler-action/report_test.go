@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/cbrgm/pr-size-labeler-action/pkg/sizer"
+)
+
+var reportLabelConfigs = []sizer.ConfigEntry{
+	{Size: "xs", Diff: 10, Files: 1, Labels: []string{"size/xs"}},
+	{Size: "s", Diff: 50, Files: 10, Labels: []string{"size/s"}},
+	{Size: "m", Diff: 100, Files: 20, Labels: []string{"size/m", "pairing-wanted"}},
+}
+
+func TestRenderSizeReport(t *testing.T) {
+	result := sizer.Result{
+		Counts:        sizer.Counts{Files: 14, Lines: 812},
+		ExcludedFiles: []string{"go.sum"},
+		FilesEntry:    reportLabelConfigs[1],
+		DiffEntry:     reportLabelConfigs[2],
+		Entry:         reportLabelConfigs[2],
+		Approximate:   true,
+	}
+
+	got := renderSizeReport(result, reportLabelConfigs)
+	for _, want := range []string{
+		"### Pull request size: `m`",
+		"Labels: `size/m`, `pairing-wanted`",
+		"| Files | 14 | `s` | > 1 |",
+		"| Lines | 812 | `m` | > 50 |",
+		"The size was decided by diff.",
+		"is approximate",
+		"<summary>1 excluded files</summary>",
+		"- `go.sum`",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("renderSizeReport() = %q, want it to contain %q", got, want)
+		}
+	}
+}
+
+func TestRenderSizeReportOptionalDimensions(t *testing.T) {
+	labelConfigs := []sizer.ConfigEntry{
+		{Size: "s", Diff: 100, Files: 10, Commits: new(5), Labels: []string{"size/s"}},
+		{Size: "m", Diff: 500, Files: 50, Commits: new(20), Labels: []string{"size/m"}},
+	}
+	result := sizer.NewResultFromCounts(sizer.Counts{Files: 2, Lines: 40, Commits: 8}, sizer.Config{LabelConfigs: labelConfigs})
+
+	got := renderSizeReport(result, labelConfigs)
+	for _, want := range []string{
+		"| Commits | 8 | `m` | > 5 |",
+		"The size was decided by commits.",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("renderSizeReport() = %q, want it to contain %q", got, want)
+		}
+	}
+	if strings.Contains(got, "Renames") {
+		t.Errorf("renderSizeReport() = %q, want no row for renames, which no size limits", got)
+	}
+}
+
+func TestRenderSizeReportManyExcludedFiles(t *testing.T) {
+	result := sizer.Result{Entry: reportLabelConfigs[0]}
+	for i := range MaxReportedExcludedFiles + 20 {
+		result.ExcludedFiles = append(result.ExcludedFiles, fmt.Sprintf("vendor/lib%d.go", i))
+	}
+
+	got := renderSizeReport(result, reportLabelConfigs)
+	if !strings.Contains(got, "<summary>70 excluded files</summary>") || !strings.Contains(got, "- …and 20 more") {
+		t.Errorf("renderSizeReport() = %q, want the total and the number of files not listed", got)
+	}
+	if strings.Contains(got, "vendor/lib50.go") {
+		t.Errorf("renderSizeReport() = %q, want at most %d files listed", got, MaxReportedExcludedFiles)
+	}
+}
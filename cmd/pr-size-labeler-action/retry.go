@@ -0,0 +1,166 @@
+package main
+
+import (
+	"context"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Retry settings for requests to the GitHub API.
+const (
+	// MaxRetries is the number of times a failed request is retried.
+	MaxRetries = 3
+	// RetryBaseDelay is the delay before the first retry, it doubles with every further retry.
+	RetryBaseDelay = time.Second
+	// RetryMaxDelay is the longest delay to wait for a retry. Requests the server asks to delay for longer are not retried.
+	RetryMaxDelay = 2 * time.Minute
+)
+
+// retryTransport retries idempotent requests failing with a server error, a network error or a rate limit.
+// It waits as long as the Retry-After or X-RateLimit-Reset headers ask for, or uses a jittered exponential backoff.
+type retryTransport struct {
+	base       http.RoundTripper
+	maxRetries int
+	baseDelay  time.Duration
+	maxDelay   time.Duration
+	sleep      func(ctx context.Context, d time.Duration) error
+}
+
+// newRetryTransport creates a retryTransport around the given transport, using http.DefaultTransport if it is nil.
+func newRetryTransport(base http.RoundTripper) *retryTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &retryTransport{
+		base:       base,
+		maxRetries: MaxRetries,
+		baseDelay:  RetryBaseDelay,
+		maxDelay:   RetryMaxDelay,
+		sleep:      sleepContext,
+	}
+}
+
+// RoundTrip sends the request and retries it if it failed temporarily.
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !isIdempotentMethod(req.Method) || (req.Body != nil && req.Body != http.NoBody && req.GetBody == nil) {
+		return t.base.RoundTrip(req)
+	}
+
+	for attempt := 0; ; attempt++ {
+		attemptReq, err := rewindRequest(req, attempt)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := t.base.RoundTrip(attemptReq)
+		delay, reason, retry := t.retryDelay(resp, err, attempt, time.Now())
+		if !retry || req.Context().Err() != nil {
+			return resp, err
+		}
+		if attempt >= t.maxRetries {
+			logger.Warningf("Giving up on %s %s after %d attempts: %s", req.Method, req.URL.Redacted(), attempt+1, reason)
+			return resp, err
+		}
+		if delay > t.maxDelay {
+			logger.Warningf("Giving up on %s %s: %s, retrying would take %s", req.Method, req.URL.Redacted(), reason, delay.Round(time.Second))
+			return resp, err
+		}
+
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			_ = resp.Body.Close()
+		}
+		logger.Infof("Retrying %s %s in %s: %s (retry %d of %d)", req.Method, req.URL.Redacted(), delay.Round(time.Millisecond), reason, attempt+1, t.maxRetries)
+		if err := t.sleep(req.Context(), delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// retryDelay checks if a request should be retried and returns how long to wait before retrying and why.
+func (t *retryTransport) retryDelay(resp *http.Response, err error, attempt int, now time.Time) (time.Duration, string, bool) {
+	if err != nil {
+		return t.backoff(attempt), err.Error(), true
+	}
+
+	switch resp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		if delay, ok := retryAfter(resp, now); ok {
+			return delay, resp.Status, true
+		}
+		return t.backoff(attempt), resp.Status, true
+	case http.StatusTooManyRequests, http.StatusForbidden:
+		if delay, ok := retryAfter(resp, now); ok {
+			return delay, "rate limit exceeded", true
+		}
+		if delay, ok := rateLimitReset(resp, now); ok {
+			return delay, "rate limit exceeded", true
+		}
+		if resp.StatusCode == http.StatusTooManyRequests {
+			return t.backoff(attempt), "rate limit exceeded", true
+		}
+	}
+	return 0, "", false
+}
+
+// backoff returns the delay before the given retry, doubling with every retry.
+// The delay is jittered between half and the full value, so concurrent clients do not retry at the same time.
+func (t *retryTransport) backoff(attempt int) time.Duration {
+	delay := t.baseDelay << attempt
+	return delay/2 + rand.N(delay/2+1)
+}
+
+// retryAfter returns the delay from the Retry-After header, given in seconds or as an HTTP date.
+func retryAfter(resp *http.Response, now time.Time) (time.Duration, bool) {
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(max(seconds, 0)) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(date.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+// rateLimitReset returns the delay until the rate limit resets if no requests are remaining.
+func rateLimitReset(resp *http.Response, now time.Time) (time.Duration, bool) {
+	if resp.Header.Get("X-RateLimit-Remaining") != "0" {
+		return 0, false
+	}
+	reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	// Wait an extra second, the reset time is rounded down to full seconds.
+	return max(time.Unix(reset, 0).Sub(now)+time.Second, 0), true
+}
+
+// isIdempotentMethod checks if a request with the given method can be sent again safely.
+func isIdempotentMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// rewindRequest returns the request to send for the given attempt, with a fresh body for every retry.
+func rewindRequest(req *http.Request, attempt int) (*http.Request, error) {
+	if attempt == 0 || req.GetBody == nil {
+		return req, nil
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	retryReq := req.Clone(req.Context())
+	retryReq.Body = body
+	return retryReq, nil
+}
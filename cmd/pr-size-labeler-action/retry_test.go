@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestRetryTransport creates a retryTransport recording its delays instead of sleeping.
+func newTestRetryTransport(delays *[]time.Duration) *retryTransport {
+	transport := newRetryTransport(nil)
+	transport.sleep = func(_ context.Context, d time.Duration) error {
+		*delays = append(*delays, d)
+		return nil
+	}
+	return transport
+}
+
+func TestRetryTransport(t *testing.T) {
+	tests := []struct {
+		name         string
+		method       string
+		responses    []int
+		headers      http.Header
+		wantStatus   int
+		wantRequests int32
+	}{
+		{"SuccessWithoutRetry", http.MethodGet, []int{200}, nil, 200, 1},
+		{"RetriesBadGateway", http.MethodGet, []int{502, 503, 200}, nil, 200, 3},
+		{"GivesUpAfterMaxRetries", http.MethodGet, []int{502, 502, 502, 502, 502}, nil, 502, MaxRetries + 1},
+		{"DoesNotRetryPost", http.MethodPost, []int{502, 200}, nil, 502, 1},
+		{"DoesNotRetryNotFound", http.MethodGet, []int{404, 200}, nil, 404, 1},
+		{"DoesNotRetryForbiddenWithoutRateLimit", http.MethodGet, []int{403, 200}, nil, 403, 1},
+		{"RetriesSecondaryRateLimit", http.MethodPut, []int{403, 200}, http.Header{"Retry-After": {"1"}}, 200, 2},
+		{"RetriesTooManyRequests", http.MethodDelete, []int{429, 200}, nil, 200, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				n := int(requests.Add(1)) - 1
+				status := tt.responses[min(n, len(tt.responses)-1)]
+				if status != http.StatusOK {
+					for key, values := range tt.headers {
+						w.Header()[key] = values
+					}
+				}
+				w.WriteHeader(status)
+			}))
+			defer server.Close()
+
+			var delays []time.Duration
+			client := &http.Client{Transport: newTestRetryTransport(&delays)}
+			req, err := http.NewRequest(tt.method, server.URL, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Fatalf("Do() error = %v", err)
+			}
+			_ = resp.Body.Close()
+
+			if resp.StatusCode != tt.wantStatus || requests.Load() != tt.wantRequests {
+				t.Errorf("Do() = status %d after %d requests, want status %d after %d requests", resp.StatusCode, requests.Load(), tt.wantStatus, tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestRetryTransportRewindsBody(t *testing.T) {
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if len(bodies) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer server.Close()
+
+	var delays []time.Duration
+	client := &http.Client{Transport: newTestRetryTransport(&delays)}
+	req, err := http.NewRequest(http.MethodPut, server.URL, strings.NewReader(`["size/m"]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	_ = resp.Body.Close()
+
+	if len(bodies) != 2 || bodies[0] != `["size/m"]` || bodies[1] != `["size/m"]` {
+		t.Errorf("request bodies = %q, want the same body twice", bodies)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	transport := newRetryTransport(nil)
+
+	newResponse := func(status int, headers map[string]string) *http.Response {
+		resp := &http.Response{StatusCode: status, Status: strconv.Itoa(status) + " " + http.StatusText(status), Header: http.Header{}}
+		for key, value := range headers {
+			resp.Header.Set(key, value)
+		}
+		return resp
+	}
+
+	tests := []struct {
+		name      string
+		resp      *http.Response
+		err       error
+		wantDelay time.Duration
+		wantRetry bool
+	}{
+		{"RetryAfterSeconds", newResponse(http.StatusForbidden, map[string]string{"Retry-After": "30"}), nil, 30 * time.Second, true},
+		{"RetryAfterDate", newResponse(http.StatusServiceUnavailable, map[string]string{"Retry-After": now.Add(time.Minute).Format(http.TimeFormat)}), nil, time.Minute, true},
+		{"RateLimitReset", newResponse(http.StatusForbidden, map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": strconv.FormatInt(now.Add(10*time.Second).Unix(), 10)}), nil, 11 * time.Second, true},
+		{"RateLimitNotExhausted", newResponse(http.StatusForbidden, map[string]string{"X-RateLimit-Remaining": "10", "X-RateLimit-Reset": strconv.FormatInt(now.Unix(), 10)}), nil, 0, false},
+		{"Success", newResponse(http.StatusOK, nil), nil, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotDelay, _, gotRetry := transport.retryDelay(tt.resp, tt.err, 0, now)
+			if gotDelay != tt.wantDelay || gotRetry != tt.wantRetry {
+				t.Errorf("retryDelay() = %v, %v, want %v, %v", gotDelay, gotRetry, tt.wantDelay, tt.wantRetry)
+			}
+		})
+	}
+
+	if _, _, retry := transport.retryDelay(nil, errors.New("connection reset by peer"), 0, now); !retry {
+		t.Errorf("retryDelay() does not retry a network error")
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	transport := newRetryTransport(nil)
+	for attempt := range 3 {
+		maxDelay := RetryBaseDelay << attempt
+		for range 20 {
+			if got := transport.backoff(attempt); got < maxDelay/2 || got > maxDelay {
+				t.Fatalf("backoff(%d) = %v, want between %v and %v", attempt, got, maxDelay/2, maxDelay)
+			}
+		}
+	}
+}
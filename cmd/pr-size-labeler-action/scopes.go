@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/cbrgm/pr-size-labeler-action/pkg/sizer"
+	"github.com/google/go-github/v90/github"
+)
+
+// allLabelConfigs returns the sizes of the configuration followed by the sizes of every scope.
+func (c Config) allLabelConfigs() []sizer.ConfigEntry {
+	labelConfigs := slices.Clone(c.LabelConfigs)
+	for _, scope := range c.Scopes {
+		labelConfigs = append(labelConfigs, c.ScopeConfig(scope).LabelConfigs...)
+	}
+	return labelConfigs
+}
+
+// scopeEntries returns the size entries of the scopes with changed files.
+func scopeEntries(result sizer.Result) []sizer.ConfigEntry {
+	entries := make([]sizer.ConfigEntry, 0, len(result.Scopes))
+	for _, scope := range result.Scopes {
+		entries = append(entries, scope.Result.Entry)
+	}
+	return entries
+}
+
+// keptScopeLabels returns the scope size labels the pull request already has if its size is approximate.
+// Scopes can not be sized without the list of files, so their labels are kept as they are.
+func keptScopeLabels(pr *github.PullRequest, config Config, result sizer.Result) []string {
+	if !result.Approximate {
+		return nil
+	}
+
+	var labels []string
+	for _, label := range pr.Labels {
+		if isSizeLabel(label.GetName(), config.allLabelConfigs()) && !isSizeLabel(label.GetName(), config.LabelConfigs) {
+			labels = append(labels, label.GetName())
+		}
+	}
+	return labels
+}
+
+// writeScopeReport writes a table with the size of every scope with changed files.
+func writeScopeReport(sb *strings.Builder, scopes []sizer.ScopeResult) {
+	if len(scopes) == 0 {
+		return
+	}
+
+	sb.WriteString("\n| Scope | Size | Lines | Files |\n")
+	sb.WriteString("|---|---|---:|---:|\n")
+	for _, scope := range scopes {
+		fmt.Fprintf(sb, "| `%s` | `%s` | %d | %d |\n", scope.Scope.Name, scope.Result.Entry.Size, scope.Result.Lines, scope.Result.Files)
+	}
+}
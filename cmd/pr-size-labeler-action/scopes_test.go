@@ -0,0 +1,70 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/cbrgm/pr-size-labeler-action/pkg/sizer"
+)
+
+var testScopeConfig = Config{Config: sizer.Config{
+	LabelConfigs: []sizer.ConfigEntry{
+		{Size: "s", Diff: 100, Files: 10, Labels: []string{"size/s"}},
+		{Size: "m", Diff: 500, Files: 50, Labels: []string{"size/m"}},
+	},
+	Scopes: []sizer.Scope{{Name: "api", Path: "services/api"}},
+}}
+
+func TestAllLabelConfigs(t *testing.T) {
+	var got []string
+	for _, entry := range testScopeConfig.allLabelConfigs() {
+		got = append(got, entry.Labels...)
+	}
+	want := []string{"size/s", "size/m", "api/size-s", "api/size-m"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("allLabelConfigs() labels = %v, want %v", got, want)
+	}
+}
+
+func TestKeptScopeLabels(t *testing.T) {
+	pr := mockPullRequest("size/m", "api/size-s", "bug")
+
+	tests := []struct {
+		name        string
+		approximate bool
+		want        []string
+	}{
+		{"ScopesSized", false, nil},
+		{"Approximate", true, []string{"api/size-s"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := sizer.Result{Approximate: tt.approximate}
+			if got := keptScopeLabels(pr, testScopeConfig, result); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("keptScopeLabels() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStaleLabelsOfScopes(t *testing.T) {
+	pr := mockPullRequest("size/m", "api/size-s", "bug")
+	if got := staleLabels(pr, testScopeConfig, []string{"size/m", "api/size-m"}); !reflect.DeepEqual(got, []string{"api/size-s"}) {
+		t.Errorf("staleLabels() = %v, want the stale scope label", got)
+	}
+}
+
+func TestRenderSizeReportScopes(t *testing.T) {
+	files := []sizer.FileChange{
+		{Filename: "services/api/main.go", Status: "modified", Additions: 150, Deletions: 50, Changes: 200},
+		{Filename: "README.md", Status: "modified", Additions: 1, Changes: 1},
+	}
+	result := sizer.Calculate(files, testScopeConfig.Config, nil)
+
+	got := renderSizeReport(result, testScopeConfig.LabelConfigs)
+	if want := "| `api` | `m` | 200 | 1 |"; !strings.Contains(got, want) {
+		t.Errorf("renderSizeReport() = %q, want it to contain %q", got, want)
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cbrgm/pr-size-labeler-action/pkg/sizer"
+	"github.com/google/go-github/v90/github"
+)
+
+// StatusContext is the context of the commit status reporting the size.
+const StatusContext = "pr-size"
+
+// Commit status states used to report the size.
+const (
+	StatusStateSuccess = "success"
+	StatusStateFailure = "failure"
+)
+
+// updateCommitStatus reports the size as a commit status on the head commit of the pull request.
+func (prp *PullRequestProcessor) updateCommitStatus(pr *github.PullRequest, result sizer.Result) error {
+	status, err := newSizeStatus(result, prp.config)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = prp.clientWrapper.client.Repositories.CreateStatus(prp.ctx, prp.repoOwner, prp.repoName, pr.GetHead().GetSHA(), status)
+	return err
+}
+
+// newSizeStatus creates the commit status for the size result.
+// The status fails if the size is bigger than the size configured in fail_above.
+func newSizeStatus(result sizer.Result, config Config) (github.RepoStatus, error) {
+	state := StatusStateSuccess
+	if config.FailAbove != "" {
+		exceeds, err := exceedsSize(config.LabelConfigs, result.Entry, config.FailAbove)
+		if err != nil {
+			return github.RepoStatus{}, err
+		}
+		if exceeds {
+			state = StatusStateFailure
+		}
+	}
+
+	return github.RepoStatus{
+		State:       github.Ptr(state),
+		Description: github.Ptr(statusDescription(result)),
+		Context:     github.Ptr(StatusContext),
+	}, nil
+}
+
+// exceedsSize checks if an entry comes after the entry with the given size in the configuration.
+func exceedsSize(labelConfigs []sizer.ConfigEntry, entry sizer.ConfigEntry, size string) (bool, error) {
+	limit := sizer.FindConfigEntryIndex(labelConfigs, size)
+	if limit < 0 {
+		return false, fmt.Errorf("fail_above references unknown size %q", size)
+	}
+	return sizer.FindConfigEntryIndex(labelConfigs, entry.Size) > limit, nil
+}
+
+// statusDescription returns the commit status description, for example "L (812 lines, 14 files)".
+func statusDescription(result sizer.Result) string {
+	approximate := ""
+	if result.Approximate {
+		approximate = "~"
+	}
+	return fmt.Sprintf("%s (%s%d lines, %s%d files)", strings.ToUpper(result.Entry.Size), approximate, result.Lines, approximate, result.Files)
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/cbrgm/pr-size-labeler-action/pkg/sizer"
+)
+
+func TestExceedsSize(t *testing.T) {
+	tests := []struct {
+		name    string
+		entry   sizer.ConfigEntry
+		size    string
+		want    bool
+		wantErr bool
+	}{
+		{"Smaller", reportLabelConfigs[0], "s", false, false},
+		{"Equal", reportLabelConfigs[1], "s", false, false},
+		{"Bigger", reportLabelConfigs[2], "s", true, false},
+		{"UnknownSize", reportLabelConfigs[2], "xxl", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := exceedsSize(reportLabelConfigs, tt.entry, tt.size)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("exceedsSize() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("exceedsSize() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewSizeStatus(t *testing.T) {
+	result := sizer.Result{Counts: sizer.Counts{Files: 14, Lines: 812}, Entry: reportLabelConfigs[2]}
+
+	tests := []struct {
+		name            string
+		result          sizer.Result
+		config          Config
+		wantState       string
+		wantDescription string
+	}{
+		{"NoFailAbove", result, Config{Config: sizer.Config{LabelConfigs: reportLabelConfigs}}, StatusStateSuccess, "M (812 lines, 14 files)"},
+		{"WithinFailAbove", result, Config{Config: sizer.Config{LabelConfigs: reportLabelConfigs}, FailAbove: "m"}, StatusStateSuccess, "M (812 lines, 14 files)"},
+		{"AboveFailAbove", result, Config{Config: sizer.Config{LabelConfigs: reportLabelConfigs}, FailAbove: "s"}, StatusStateFailure, "M (812 lines, 14 files)"},
+		{"Approximate", sizer.Result{Counts: sizer.Counts{Files: 3500, Lines: 20000}, Entry: reportLabelConfigs[2], Approximate: true}, Config{Config: sizer.Config{LabelConfigs: reportLabelConfigs}}, StatusStateSuccess, "M (~20000 lines, ~3500 files)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newSizeStatus(tt.result, tt.config)
+			if err != nil {
+				t.Fatalf("newSizeStatus() error = %v", err)
+			}
+			if got.GetState() != tt.wantState || got.GetDescription() != tt.wantDescription || got.GetContext() != StatusContext {
+				t.Errorf("newSizeStatus() = %v, %v, %v, want %v, %v, %v", got.GetState(), got.GetDescription(), got.GetContext(), tt.wantState, tt.wantDescription, StatusContext)
+			}
+		})
+	}
+
+	if _, err := newSizeStatus(result, Config{Config: sizer.Config{LabelConfigs: reportLabelConfigs}, FailAbove: "xxl"}); err == nil {
+		t.Errorf("newSizeStatus() expected an error for an unknown fail_above size")
+	}
+}
@@ -4,6 +4,8 @@ go 1.26.5
 
 require (
 	github.com/alexflint/go-arg v1.6.1
+	github.com/bmatcuk/doublestar/v4 v4.10.0
+	github.com/expr-lang/expr v1.17.8
 	github.com/google/go-github/v90 v90.0.0
 	golang.org/x/oauth2 v0.36.0
 	gopkg.in/yaml.v3 v3.0.1
@@ -0,0 +1,174 @@
+package sizer
+
+import (
+	"math"
+	"strings"
+)
+
+// Parameter names of the optional dimensions a size can be limited by.
+const (
+	ParamNameCommits     = "commits"
+	ParamNameDirectories = "directories"
+	ParamNameDeletions   = "deletions"
+	ParamNameRenames     = "renames"
+)
+
+// ParamNames lists all dimensions in the order they are reported.
+var ParamNames = []string{ParamNameFiles, ParamNameDiff, ParamNameCommits, ParamNameDirectories, ParamNameDeletions, ParamNameRenames}
+
+// Values of ConfigEntry.Match, deciding when the changes are big enough for an entry.
+const (
+	// MatchAny applies an entry if the changes exceed any threshold of the entry before it. It is the default.
+	MatchAny = "any"
+	// MatchAll applies an entry only if the changes exceed all thresholds of the entry before it.
+	MatchAll = "all"
+)
+
+// StatusRenamed is the status of a file change renaming the file.
+const StatusRenamed = "renamed"
+
+// Counts holds the measured dimensions of a set of changes.
+type Counts struct {
+	Files       int
+	Lines       int
+	Commits     int
+	Directories int // Directories is the number of distinct top-level directories, files in the root count as one.
+	Deletions   int
+	Renames     int
+}
+
+// Count returns the count of the dimension with the given parameter name.
+func (c Counts) Count(paramName string) int {
+	switch paramName {
+	case ParamNameFiles:
+		return c.Files
+	case ParamNameDiff:
+		return c.Lines
+	case ParamNameCommits:
+		return c.Commits
+	case ParamNameDirectories:
+		return c.Directories
+	case ParamNameDeletions:
+		return c.Deletions
+	case ParamNameRenames:
+		return c.Renames
+	}
+	return 0
+}
+
+// CountChanges measures the changed files in every dimension except commits, which are not known from the files.
+// Files matching exclude_files or marked generated or vendored by the attributes are skipped.
+// Deleted lines are weighted like changed lines, the other dimensions are not.
+func CountChanges(files []FileChange, config Config, attributes LinguistAttributes) Counts {
+	numberOfFiles, numberOfLines := CalculateSizeAndDiff(files, config, attributes)
+	counts := Counts{Files: numberOfFiles, Lines: numberOfLines}
+
+	directories := make(map[string]bool)
+	deletions := 0.0
+	for _, file := range files {
+		if (config.AddedLinesOnly && file.Status == StatusRemoved) || IsExcluded(file.Filename, config, attributes) {
+			continue
+		}
+
+		directories[topLevelDirectory(file.Filename)] = true
+		deletions += config.Weights.WeightFor(file.Filename) * float64(file.Deletions)
+		if file.Status == StatusRenamed {
+			counts.Renames++
+		}
+	}
+	counts.Directories = len(directories)
+	counts.Deletions = int(math.Round(deletions))
+	return counts
+}
+
+// topLevelDirectory returns the first path element of a directory containing the file, or "" for files in the root.
+func topLevelDirectory(filename string) string {
+	directory, _, found := strings.Cut(filename, "/")
+	if !found {
+		return ""
+	}
+	return directory
+}
+
+// Threshold returns the threshold of the entry for the given dimension.
+// It returns false if the entry does not limit the dimension.
+func (e ConfigEntry) Threshold(paramName string) (int, bool) {
+	var threshold *int
+	switch paramName {
+	case ParamNameFiles:
+		return e.Files, true
+	case ParamNameDiff:
+		return e.Diff, true
+	case ParamNameCommits:
+		threshold = e.Commits
+	case ParamNameDirectories:
+		threshold = e.Directories
+	case ParamNameDeletions:
+		threshold = e.Deletions
+	case ParamNameRenames:
+		threshold = e.Renames
+	}
+
+	if threshold == nil {
+		return 0, false
+	}
+	return *threshold, true
+}
+
+// Fits checks if the changes stay within all thresholds of the entry.
+func (e ConfigEntry) Fits(counts Counts) bool {
+	_, exceeded := e.compare(counts)
+	return exceeded == 0
+}
+
+// Applies checks if the changes are big enough for the entry, judged by the thresholds of the entry before it.
+// With MatchAll, the changes have to exceed all thresholds of the previous entry, otherwise any of them.
+func (e ConfigEntry) Applies(previous ConfigEntry, counts Counts) bool {
+	within, exceeded := previous.compare(counts)
+	if e.Match == MatchAll {
+		return within == 0 && exceeded > 0
+	}
+	return exceeded > 0
+}
+
+// compare counts the thresholds of the entry the changes stay within and the ones they exceed.
+func (e ConfigEntry) compare(counts Counts) (within, exceeded int) {
+	for _, paramName := range ParamNames {
+		threshold, ok := e.Threshold(paramName)
+		if !ok {
+			continue
+		}
+		if counts.Count(paramName) <= threshold {
+			within++
+		} else {
+			exceeded++
+		}
+	}
+	return within, exceeded
+}
+
+// SelectEntry returns the biggest entry that applies to the changes, or the first entry if none of the others does.
+// The match of an entry only affects whether that entry applies, so a bigger entry with MatchAny still applies
+// to changes exceeding any threshold of the entry before it.
+func SelectEntry(labelConfigs []ConfigEntry, counts Counts) ConfigEntry {
+	if len(labelConfigs) == 0 {
+		return ConfigEntry{}
+	}
+
+	for i := len(labelConfigs) - 1; i > 0; i-- {
+		if labelConfigs[i].Applies(labelConfigs[i-1], counts) {
+			return labelConfigs[i]
+		}
+	}
+	return labelConfigs[0]
+}
+
+// IsParamConfigured checks if any entry sets a threshold for the given dimension. Files and diff are always set.
+func IsParamConfigured(labelConfigs []ConfigEntry, paramName string) bool {
+	for _, entry := range labelConfigs {
+		if _, ok := entry.Threshold(paramName); ok {
+			return true
+		}
+	}
+	return false
+}
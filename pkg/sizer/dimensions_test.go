@@ -0,0 +1,146 @@
+package sizer
+
+import (
+	"testing"
+)
+
+func TestCountChanges(t *testing.T) {
+	files := []FileChange{
+		{Filename: "README.md", Status: "modified", Additions: 5, Deletions: 5, Changes: 10},
+		{Filename: "cmd/main.go", Status: "modified", Additions: 20, Deletions: 10, Changes: 30},
+		{Filename: "cmd/util/util.go", Status: "renamed", Additions: 0, Deletions: 0, Changes: 0},
+		{Filename: "pkg/old.go", Status: "removed", Additions: 0, Deletions: 40, Changes: 40},
+		{Filename: "vendor/lib/lib.go", Status: "renamed", Additions: 100, Deletions: 100, Changes: 200},
+		{Filename: "docs/guide.md", Status: "modified", Additions: 10, Deletions: 10, Changes: 20},
+	}
+	config := Config{
+		ExcludeFiles: []string{"vendor/**"},
+		Weights:      Weights{{Pattern: "docs/**", Weight: 0.5}},
+	}
+
+	tests := []struct {
+		name           string
+		addedLinesOnly bool
+		want           Counts
+	}{
+		{"AllChanges", false, Counts{Files: 5, Lines: 90, Directories: 4, Deletions: 60, Renames: 1}},
+		{"AddedLinesOnly", true, Counts{Files: 4, Lines: 30, Directories: 3, Deletions: 20, Renames: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config.AddedLinesOnly = tt.addedLinesOnly
+			if got := CountChanges(files, config, nil); got != tt.want {
+				t.Errorf("CountChanges() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigEntryFits(t *testing.T) {
+	tests := []struct {
+		name   string
+		entry  ConfigEntry
+		counts Counts
+		want   bool
+	}{
+		{"WithinFilesAndDiff", ConfigEntry{Diff: 100, Files: 10}, Counts{Files: 10, Lines: 100}, true},
+		{"ExceedsDiff", ConfigEntry{Diff: 100, Files: 10}, Counts{Files: 1, Lines: 101}, false},
+		{"UnsetThresholdDoesNotLimit", ConfigEntry{Diff: 100, Files: 10}, Counts{Files: 1, Lines: 1, Commits: 500}, true},
+		{"ExceedsCommits", ConfigEntry{Diff: 100, Files: 10, Commits: new(5)}, Counts{Files: 1, Lines: 1, Commits: 6}, false},
+		{"MatchAllIgnored", ConfigEntry{Diff: 100, Files: 10, Match: MatchAll}, Counts{Files: 1, Lines: 101}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.entry.Fits(tt.counts); got != tt.want {
+				t.Errorf("Fits() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigEntryApplies(t *testing.T) {
+	previous := ConfigEntry{Diff: 100, Files: 10, Directories: new(2)}
+
+	tests := []struct {
+		name   string
+		match  string
+		counts Counts
+		want   bool
+	}{
+		{"Within", MatchAny, Counts{Files: 10, Lines: 100, Directories: 2}, false},
+		{"ExceedsSome", MatchAny, Counts{Files: 20, Lines: 200, Directories: 1}, true},
+		{"MatchAllWithin", MatchAll, Counts{Files: 10, Lines: 100, Directories: 2}, false},
+		{"MatchAllExceedsSome", MatchAll, Counts{Files: 20, Lines: 200, Directories: 1}, false},
+		{"MatchAllExceedsAll", MatchAll, Counts{Files: 20, Lines: 200, Directories: 3}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := (ConfigEntry{Match: tt.match}).Applies(previous, tt.counts); got != tt.want {
+				t.Errorf("Applies() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelectEntry(t *testing.T) {
+	labelConfigs := []ConfigEntry{
+		{Size: "s", Diff: 100, Files: 10, Deletions: new(50), Labels: []string{"size/s"}},
+		{Size: "m", Diff: 500, Files: 50, Renames: new(10), Labels: []string{"size/m"}},
+		{Size: "l", Diff: 1000, Files: 100, Match: MatchAll, Labels: []string{"size/l"}},
+		{Size: "xl", Diff: 5000, Files: 500, Labels: []string{"size/xl"}},
+	}
+
+	tests := []struct {
+		name   string
+		counts Counts
+		want   string
+	}{
+		{"Smallest", Counts{Files: 1, Lines: 10}, "s"},
+		{"DeletionsExceedSmallest", Counts{Files: 1, Lines: 60, Deletions: 60}, "m"},
+		{"MatchAllKeepsSize", Counts{Files: 80, Lines: 800, Renames: 2}, "m"},
+		{"MatchAllExceeded", Counts{Files: 80, Lines: 800, Renames: 20}, "l"},
+		{"BiggerSizeWithMatchAny", Counts{Files: 1, Lines: 1200}, "xl"},
+		{"TooBigForAll", Counts{Files: 1000, Lines: 10000, Renames: 20}, "xl"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SelectEntry(labelConfigs, tt.counts); got.Size != tt.want {
+				t.Errorf("SelectEntry() = %v, want size %v", got, tt.want)
+			}
+		})
+	}
+
+	if got := SelectEntry(nil, Counts{}); got.Size != "" {
+		t.Errorf("SelectEntry() without entries = %v, want an empty entry", got)
+	}
+}
+
+func TestResultDecidedByOptionalDimensions(t *testing.T) {
+	labelConfigs := []ConfigEntry{
+		{Size: "s", Diff: 100, Files: 10, Commits: new(5), Labels: []string{"size/s"}},
+		{Size: "m", Diff: 500, Files: 50, Commits: new(20), Labels: []string{"size/m"}},
+	}
+	config := Config{LabelConfigs: labelConfigs}
+
+	tests := []struct {
+		name   string
+		counts Counts
+		want   string
+	}{
+		{"Commits", Counts{Files: 1, Lines: 10, Commits: 10}, "commits"},
+		{"AllDimensions", Counts{Files: 1, Lines: 10, Commits: 1}, "files, diff and commits"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := NewResultFromCounts(tt.counts, config)
+			if got := result.DecidedBy(labelConfigs); got != tt.want {
+				t.Errorf("DecidedBy() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package sizer
+
+import (
+	"strings"
+)
+
+// Linguist attributes marking files that should not count towards the size.
+const (
+	AttrLinguistGenerated = "linguist-generated"
+	AttrLinguistVendored  = "linguist-vendored"
+)
+
+// linguistRule holds the linguist attributes a single .gitattributes line sets.
+// A nil value means the line does not touch the attribute.
+type linguistRule struct {
+	pattern   string
+	generated *bool
+	vendored  *bool
+}
+
+// LinguistAttributes holds the linguist rules of a .gitattributes file in order.
+type LinguistAttributes []linguistRule
+
+// ParseGitAttributes parses the linguist-generated and linguist-vendored attributes from a .gitattributes file.
+func ParseGitAttributes(content string) LinguistAttributes {
+	var attributes LinguistAttributes
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		rule := linguistRule{pattern: fields[0]}
+		for _, attr := range fields[1:] {
+			name, value := parseAttribute(attr)
+			switch name {
+			case AttrLinguistGenerated:
+				rule.generated = &value
+			case AttrLinguistVendored:
+				rule.vendored = &value
+			}
+		}
+		if rule.generated != nil || rule.vendored != nil {
+			attributes = append(attributes, rule)
+		}
+	}
+	return attributes
+}
+
+// parseAttribute splits a single attribute into its name and boolean state.
+// Set ("attr", "attr=true") is true; unset ("-attr"), unspecified ("!attr") and any other value are false.
+func parseAttribute(attr string) (string, bool) {
+	switch {
+	case strings.HasPrefix(attr, "-"), strings.HasPrefix(attr, "!"):
+		return attr[1:], false
+	case strings.Contains(attr, "="):
+		name, value, _ := strings.Cut(attr, "=")
+		return name, value == "true"
+	default:
+		return attr, true
+	}
+}
+
+// Excludes checks if a file is marked as generated or vendored.
+// Like git, the last line matching the file decides the state of each attribute.
+func (a LinguistAttributes) Excludes(filename string) bool {
+	generated, vendored := false, false
+	for _, rule := range a {
+		if !matchesAttributePattern(filename, rule.pattern) {
+			continue
+		}
+		if rule.generated != nil {
+			generated = *rule.generated
+		}
+		if rule.vendored != nil {
+			vendored = *rule.vendored
+		}
+	}
+	return generated || vendored
+}
@@ -0,0 +1,76 @@
+package sizer
+
+import "testing"
+
+func TestParseAttribute(t *testing.T) {
+	tests := []struct {
+		attr      string
+		wantName  string
+		wantValue bool
+	}{
+		{"linguist-generated", "linguist-generated", true},
+		{"linguist-generated=true", "linguist-generated", true},
+		{"linguist-generated=false", "linguist-generated", false},
+		{"-linguist-generated", "linguist-generated", false},
+		{"!linguist-vendored", "linguist-vendored", false},
+		{"text=auto", "text", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.attr, func(t *testing.T) {
+			gotName, gotValue := parseAttribute(tt.attr)
+			if gotName != tt.wantName || gotValue != tt.wantValue {
+				t.Errorf("parseAttribute(%v) = %v, %v, want %v, %v", tt.attr, gotName, gotValue, tt.wantName, tt.wantValue)
+			}
+		})
+	}
+}
+
+func TestLinguistAttributesExcludes(t *testing.T) {
+	attributes := ParseGitAttributes(`# Generated code
+*.pb.go linguist-generated
+**/mocks/** linguist-generated=true
+go.sum linguist-generated -diff
+third_party/** linguist-vendored
+third_party/ours/** -linguist-vendored
+*.go text eol=lf
+docs/ linguist-generated
+`)
+
+	tests := []struct {
+		name     string
+		filename string
+		want     bool
+	}{
+		{"GeneratedByExtension", "api/v1/service.pb.go", true},
+		{"GeneratedInNestedDirectory", "internal/store/mocks/store.go", true},
+		{"GeneratedWithOtherAttributes", "go.sum", true},
+		{"Vendored", "third_party/lib/lib.go", true},
+		{"VendoredUnsetByLaterLine", "third_party/ours/lib.go", false},
+		{"DirectoryPatternDoesNotMatchFiles", "docs/readme.md", false},
+		{"RegularFile", "main.go", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := attributes.Excludes(tt.filename); got != tt.want {
+				t.Errorf("Excludes(%v) = %v, want %v", tt.filename, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalculateSizeAndDiffWithAttributes(t *testing.T) {
+	attributes := ParseGitAttributes("*.pb.go linguist-generated\n")
+	config := Config{ExcludeFiles: []string{"exclude.*"}}
+	files := []FileChange{
+		mockFileChange("service.pb.go", "modified", 500, 400),
+		mockFileChange("exclude.txt", "modified", 100, 90),
+		mockFileChange("service.go", "modified", 20, 10),
+	}
+
+	gotNumberOfFiles, gotNumberOfLines := CalculateSizeAndDiff(files, config, attributes)
+	if gotNumberOfFiles != 1 || gotNumberOfLines != 20 {
+		t.Errorf("CalculateSizeAndDiff() = files: %d, want files: 1, lines: %d, want lines: 20", gotNumberOfFiles, gotNumberOfLines)
+	}
+}
@@ -0,0 +1,82 @@
+package sizer
+
+import (
+	"path"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// ShouldExcludeFile checks if a file should be excluded based on the configuration.
+// Patterns are evaluated in order like a .gitignore file, so a later pattern prefixed
+// with '!' re-includes files excluded by an earlier one. Invalid patterns never match.
+func ShouldExcludeFile(filename string, patterns []string) bool {
+	return MatchesPatterns(filename, patterns)
+}
+
+// MatchesPatterns checks if a file matches a list of patterns evaluated in order like a .gitignore file.
+// A later pattern prefixed with '!' unmatches files matched by an earlier one. Invalid patterns never match.
+func MatchesPatterns(filename string, patterns []string) bool {
+	matched := false
+	for _, pattern := range patterns {
+		negated := strings.HasPrefix(pattern, "!")
+		if MatchesPattern(filename, strings.TrimPrefix(pattern, "!")) {
+			matched = !negated
+		}
+	}
+	return matched
+}
+
+// MatchesPattern checks if a filename matches a gitignore-style pattern.
+// A pattern without a slash matches at any depth, a pattern containing a slash is
+// anchored to the repository root, '**' matches any number of directories and a
+// pattern matching a directory matches every file below it.
+func MatchesPattern(filename, pattern string) bool {
+	pattern, dirOnly, ok := normalizePattern(pattern)
+	if !ok {
+		return false
+	}
+	filename = strings.TrimPrefix(filename, "/")
+
+	if !dirOnly && doublestar.MatchUnvalidated(pattern, filename) {
+		return true
+	}
+
+	// Check the parent directories, matching a directory matches everything below it
+	for dir := path.Dir(filename); dir != "."; dir = path.Dir(dir) {
+		if doublestar.MatchUnvalidated(pattern, dir) {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidatePattern checks if a pattern is valid. A leading '!' is allowed.
+func ValidatePattern(pattern string) bool {
+	return doublestar.ValidatePattern(strings.TrimPrefix(pattern, "!"))
+}
+
+// matchesAttributePattern checks if a filename matches a .gitattributes pattern.
+// Unlike MatchesPattern, a pattern matching a directory does not match the files below it.
+func matchesAttributePattern(filename, pattern string) bool {
+	pattern, dirOnly, ok := normalizePattern(pattern)
+	if !ok || dirOnly {
+		return false
+	}
+	return doublestar.MatchUnvalidated(pattern, strings.TrimPrefix(filename, "/"))
+}
+
+// normalizePattern converts a gitignore-style pattern into a doublestar pattern relative to the repository root.
+// It reports whether the pattern only matches directories and whether it is valid.
+func normalizePattern(pattern string) (string, bool, bool) {
+	if pattern == "" || !doublestar.ValidatePattern(pattern) {
+		return "", false, false
+	}
+
+	dirOnly := strings.HasSuffix(pattern, "/")
+	pattern = strings.TrimSuffix(pattern, "/")
+	if !strings.Contains(pattern, "/") {
+		pattern = "**/" + pattern
+	}
+	return strings.TrimPrefix(pattern, "/"), dirOnly, true
+}
@@ -0,0 +1,190 @@
+package sizer
+
+import "testing"
+
+func TestShouldExcludeFile(t *testing.T) {
+	tests := []struct {
+		name       string
+		filename   string
+		patterns   []string
+		wantResult bool
+	}{
+		{
+			name:       "exclude specific file",
+			filename:   "foo.bar",
+			patterns:   []string{"foo.bar"},
+			wantResult: true,
+		},
+		{
+			name:       "exclude by extension",
+			filename:   "example.xyz",
+			patterns:   []string{"*.xyz"},
+			wantResult: true,
+		},
+		{
+			name:       "do not exclude unrelated file",
+			filename:   "test.txt",
+			patterns:   []string{"*.xyz"},
+			wantResult: false,
+		},
+		{
+			name:       "exclude with full path",
+			filename:   "/path/to/file/foo.bar",
+			patterns:   []string{"/path/to/file/foo.bar"},
+			wantResult: true,
+		},
+		{
+			name:       "exclude with wildcard in path",
+			filename:   "/some/path/example.txt",
+			patterns:   []string{"/some/path/*"},
+			wantResult: true,
+		},
+		{
+			name:       "do not exclude when path pattern does not match",
+			filename:   "/another/path/example.txt",
+			patterns:   []string{"/some/path/*"},
+			wantResult: false,
+		},
+		{
+			name:       "exclude with complex path pattern",
+			filename:   "/complex/path/with/multiple/sections/file.xyz",
+			patterns:   []string{"/complex/path/*/multiple/*/file.xyz"},
+			wantResult: true,
+		},
+		{
+			name:       "exclude nested directory file",
+			filename:   "/nested/directory/structure/file.txt",
+			patterns:   []string{"/nested/directory/*"},
+			wantResult: true,
+		},
+		{
+			name:       "exclude using multiple patterns",
+			filename:   "multiple.patterns.match",
+			patterns:   []string{"*.patterns.*", "multiple.*"},
+			wantResult: true,
+		},
+		{
+			name:       "do not exclude when multiple patterns do not match",
+			filename:   "no.pattern.match",
+			patterns:   []string{"*.patterns.*", "multiple.*"},
+			wantResult: false,
+		},
+		{
+			name:       "exclude using complex wildcard patterns",
+			filename:   "/var/log/app.log",
+			patterns:   []string{"/var/*/app.*", "*.log"},
+			wantResult: true,
+		},
+		{
+			name:       "exclude file in root directory",
+			filename:   "/rootfile.txt",
+			patterns:   []string{"/*.txt"},
+			wantResult: true,
+		},
+		{
+			name:       "do not exclude file in non-root directory with root pattern",
+			filename:   "/dir/rootfile.txt",
+			patterns:   []string{"/*.txt"},
+			wantResult: false,
+		},
+		{
+			name:       "exclude using pattern with escaped special characters",
+			filename:   "file_with_underscores_and_numbers_123.txt",
+			patterns:   []string{"file_with_underscores_and_numbers_*.txt"},
+			wantResult: true,
+		},
+		{
+			name:       "exclude using pattern with question mark",
+			filename:   "file?.txt",
+			patterns:   []string{"file?.txt"},
+			wantResult: true,
+		},
+		{
+			name:       "exclude using doublestar across directories",
+			filename:   "vendor/github.com/foo/bar.go",
+			patterns:   []string{"vendor/**/*.go"},
+			wantResult: true,
+		},
+		{
+			name:       "do not exclude doublestar pattern with other extension",
+			filename:   "vendor/github.com/foo/README.md",
+			patterns:   []string{"vendor/**/*.go"},
+			wantResult: false,
+		},
+		{
+			name:       "exclude file in nested directory with leading doublestar",
+			filename:   "pkg/api/api_test.go",
+			patterns:   []string{"**/*_test.go"},
+			wantResult: true,
+		},
+		{
+			name:       "do not exclude sibling directory sharing a prefix",
+			filename:   "vendor-tools/main.go",
+			patterns:   []string{"vendor/*"},
+			wantResult: false,
+		},
+		{
+			name:       "exclude everything below a directory pattern",
+			filename:   "vendor/github.com/foo/bar.go",
+			patterns:   []string{"vendor/"},
+			wantResult: true,
+		},
+		{
+			name:       "do not exclude file with directory-only pattern",
+			filename:   "docs",
+			patterns:   []string{"docs/"},
+			wantResult: false,
+		},
+		{
+			name:       "exclude directory name at any depth",
+			filename:   "web/node_modules/react/index.js",
+			patterns:   []string{"node_modules"},
+			wantResult: true,
+		},
+		{
+			name:       "do not exclude anchored pattern below the root",
+			filename:   "web/vendor/lib.js",
+			patterns:   []string{"/vendor"},
+			wantResult: false,
+		},
+		{
+			name:       "re-include file with negated pattern",
+			filename:   "vendor/ours/lib.go",
+			patterns:   []string{"vendor/**", "!vendor/ours/**"},
+			wantResult: false,
+		},
+		{
+			name:       "keep excluding files not matched by negated pattern",
+			filename:   "vendor/theirs/lib.go",
+			patterns:   []string{"vendor/**", "!vendor/ours/**"},
+			wantResult: true,
+		},
+		{
+			name:       "later pattern overrides negation",
+			filename:   "vendor/ours/generated.go",
+			patterns:   []string{"vendor/**", "!vendor/ours/**", "*generated.go"},
+			wantResult: true,
+		},
+		{
+			name:       "negated pattern alone does not exclude",
+			filename:   "main.go",
+			patterns:   []string{"!main.go"},
+			wantResult: false,
+		},
+		{
+			name:       "skip invalid pattern",
+			filename:   "main.go",
+			patterns:   []string{"[main.go", "*.go"},
+			wantResult: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ShouldExcludeFile(tt.filename, tt.patterns)
+			if result != tt.wantResult {
+				t.Errorf("ShouldExcludeFile(%v, %v) = %v, want %v", tt.filename, tt.patterns, result, tt.wantResult)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package sizer
+
+import (
+	"strings"
+)
+
+// Scope sizes the changes below a path prefix independently, such as a service of a monorepo.
+type Scope struct {
+	Name string `yaml:"name"`
+	Path string `yaml:"path"`
+	// LabelConfigs is the ladder of sizes of the scope. Entries without labels get the label "<name>/size-<size>".
+	// If it is empty, the sizes of the configuration are used with these labels.
+	LabelConfigs []ConfigEntry `yaml:"label_configs"`
+}
+
+// ScopeResult is the size of the changes in a scope.
+type ScopeResult struct {
+	Scope  Scope
+	Result Result
+}
+
+// Contains checks if a file is below the path prefix of the scope.
+func (s Scope) Contains(filename string) bool {
+	prefix := strings.Trim(s.Path, "/")
+	return prefix == "" || filename == prefix || strings.HasPrefix(filename, prefix+"/")
+}
+
+// ScopeConfig returns the configuration to size the scope with. It uses the exclusions, weights and
+// counting rules of the configuration, with the ladder of the scope and its default labels.
+func (c Config) ScopeConfig(scope Scope) Config {
+	labelConfigs := scope.LabelConfigs
+	inherited := len(labelConfigs) == 0
+	if inherited {
+		labelConfigs = c.LabelConfigs
+	}
+
+	scoped := make([]ConfigEntry, 0, len(labelConfigs))
+	for _, entry := range labelConfigs {
+		if inherited || len(entry.Labels) == 0 {
+			entry.Labels = []string{scope.Name + "/size-" + entry.Size}
+		}
+		scoped = append(scoped, entry)
+	}
+
+	config := c
+	config.LabelConfigs = scoped
+	config.Scopes = nil
+	return config
+}
+
+// FilesInScope returns the changed files below the path prefix of the scope.
+func FilesInScope(files []FileChange, scope Scope) []FileChange {
+	var scoped []FileChange
+	for _, file := range files {
+		if scope.Contains(file.Filename) {
+			scoped = append(scoped, file)
+		}
+	}
+	return scoped
+}
+
+// CalculateScopes sizes the changes of every scope independently. Scopes without changed files are skipped.
+// CalculateWithCommits calls it for the scopes of the configuration.
+func CalculateScopes(files []FileChange, commits int, config Config, attributes LinguistAttributes) []ScopeResult {
+	var results []ScopeResult
+	for _, scope := range config.Scopes {
+		scoped := FilesInScope(files, scope)
+		if len(scoped) == 0 {
+			continue
+		}
+		results = append(results, ScopeResult{
+			Scope:  scope,
+			Result: CalculateWithCommits(scoped, commits, config.ScopeConfig(scope), attributes),
+		})
+	}
+	return results
+}
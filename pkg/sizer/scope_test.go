@@ -0,0 +1,92 @@
+package sizer
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestScopeContains(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		filename string
+		want     bool
+	}{
+		{"BelowPath", "services/api", "services/api/main.go", true},
+		{"TrailingSlash", "services/api/", "services/api/main.go", true},
+		{"SiblingWithSamePrefix", "services/api", "services/api-gateway/main.go", false},
+		{"OutsidePath", "services/api", "docs/api.md", false},
+		{"PathIsFile", "Makefile", "Makefile", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := (Scope{Path: tt.path}).Contains(tt.filename); got != tt.want {
+				t.Errorf("Contains() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScopeConfig(t *testing.T) {
+	config := Config{
+		ExcludeFiles: []string{"go.sum"},
+		LabelConfigs: []ConfigEntry{
+			{Size: "s", Diff: 100, Files: 10, Labels: []string{"size/s"}},
+			{Size: "m", Diff: 500, Files: 50, Labels: []string{"size/m"}},
+		},
+		Scopes: []Scope{{Name: "api", Path: "services/api"}},
+	}
+
+	inherited := config.ScopeConfig(Scope{Name: "api", Path: "services/api"})
+	if len(inherited.LabelConfigs) != 2 || !slices.Equal(inherited.LabelConfigs[1].Labels, []string{"api/size-m"}) {
+		t.Errorf("ScopeConfig() label configs = %+v, want the sizes of the configuration with scope labels", inherited.LabelConfigs)
+	}
+	if inherited.Scopes != nil || !slices.Equal(inherited.ExcludeFiles, config.ExcludeFiles) {
+		t.Errorf("ScopeConfig() = %+v, want the exclusions of the configuration without scopes", inherited)
+	}
+	if !slices.Equal(config.LabelConfigs[1].Labels, []string{"size/m"}) {
+		t.Errorf("ScopeConfig() changed the labels of the configuration to %v", config.LabelConfigs[1].Labels)
+	}
+
+	own := config.ScopeConfig(Scope{Name: "web", Path: "web", LabelConfigs: []ConfigEntry{
+		{Size: "small", Diff: 50, Files: 5},
+		{Size: "big", Diff: 200, Files: 20, Labels: []string{"web-big"}},
+	}})
+	if !slices.Equal(own.LabelConfigs[0].Labels, []string{"web/size-small"}) || !slices.Equal(own.LabelConfigs[1].Labels, []string{"web-big"}) {
+		t.Errorf("ScopeConfig() label configs = %+v, want default labels only for entries without labels", own.LabelConfigs)
+	}
+}
+
+func TestCalculateScopes(t *testing.T) {
+	config := Config{
+		LabelConfigs: []ConfigEntry{
+			{Size: "s", Diff: 100, Files: 10, Labels: []string{"size/s"}},
+			{Size: "m", Diff: 500, Files: 50, Labels: []string{"size/m"}},
+		},
+		Scopes: []Scope{
+			{Name: "api", Path: "services/api"},
+			{Name: "web", Path: "services/web"},
+			{Name: "docs", Path: "docs"},
+		},
+	}
+	files := []FileChange{
+		{Filename: "services/api/main.go", Status: "modified", Additions: 150, Deletions: 50, Changes: 200},
+		{Filename: "services/web/app.ts", Status: "modified", Additions: 10, Changes: 10},
+		{Filename: "README.md", Status: "modified", Additions: 1, Changes: 1},
+	}
+
+	result := Calculate(files, config, nil)
+	if result.Entry.Size != "m" {
+		t.Errorf("Calculate() size = %v, want m for all files", result.Entry.Size)
+	}
+
+	var got []string
+	for _, scope := range result.Scopes {
+		got = append(got, scope.Scope.Name+":"+scope.Result.Entry.Size+":"+scope.Result.Entry.Labels[0])
+	}
+	want := []string{"api:m:api/size-m", "web:s:web/size-s"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Calculate() scopes = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,252 @@
+// Package sizer calculates the size of a set of changed files based on a ladder of
+// configurable sizes. It does not depend on where the changes come from, so it can be
+// used with pull requests, local git diffs or any other source of file changes.
+package sizer
+
+import (
+	"math"
+	"strings"
+)
+
+// Parameter names of the dimensions a size is calculated from.
+const (
+	ParamNameFiles = "files"
+	ParamNameDiff  = "diff"
+)
+
+// StatusRemoved is the status of a file change deleting the file.
+const StatusRemoved = "removed"
+
+// ConfigEntry defines a single configuration entry for label assignment.
+type ConfigEntry struct {
+	Size   string   `yaml:"size"`
+	Diff   int      `yaml:"diff"`
+	Files  int      `yaml:"files"`
+	Labels []string `yaml:"labels"` // Updated to support multiple labels
+	// Commits, Directories, Deletions and Renames are optional thresholds, an unset threshold does not limit the size.
+	Commits     *int   `yaml:"commits"`
+	Directories *int   `yaml:"directories"`
+	Deletions   *int   `yaml:"deletions"`
+	Renames     *int   `yaml:"renames"`
+	Match       string `yaml:"match"` // Match is MatchAny or MatchAll and decides when the thresholds of the entry before it are outgrown.
+	// When is an optional expression selecting the entry before the thresholds are checked, see WhenEnv.
+	When string `yaml:"when"`
+	// Color and Description are applied to the labels of the entry in the repository, if set.
+	Color       string `yaml:"color"`
+	Description string `yaml:"description"`
+}
+
+// Config holds the configuration for calculating sizes.
+type Config struct {
+	ExcludeFiles   []string      `yaml:"exclude_files"`
+	LabelConfigs   []ConfigEntry `yaml:"label_configs"`
+	AddedLinesOnly bool          `yaml:"added_lines_only"`
+	Weights        Weights       `yaml:"weights"`
+	Scopes         []Scope       `yaml:"scopes"` // Scopes are sized independently, in addition to all changes.
+}
+
+// DefaultLabelConfigs returns the built-in ladder of sizes from xs to xl.
+func DefaultLabelConfigs() []ConfigEntry {
+	return []ConfigEntry{
+		{Size: "xs", Diff: 25, Files: 1, Labels: []string{"size/xs"}},
+		{Size: "s", Diff: 150, Files: 10, Labels: []string{"size/s"}},
+		{Size: "m", Diff: 600, Files: 25, Labels: []string{"size/m"}},
+		{Size: "l", Diff: 2500, Files: 50, Labels: []string{"size/l"}},
+		{Size: "xl", Diff: 5000, Files: 100, Labels: []string{"size/xl"}},
+	}
+}
+
+// FileChange describes a changed file independently of where the change comes from.
+type FileChange struct {
+	Filename  string
+	Status    string // Status uses the GitHub values, such as "added", "modified", "removed" or "renamed".
+	Additions int
+	Deletions int
+	Changes   int
+}
+
+// Result describes the size of a set of changes and how it was determined.
+type Result struct {
+	Counts
+	ExcludedFiles []string
+	FilesEntry    ConfigEntry   // FilesEntry is the entry matching the number of files.
+	DiffEntry     ConfigEntry   // DiffEntry is the entry matching the number of changed lines.
+	Entry         ConfigEntry   // Entry is the first entry matching its when expression or the changes fit and decides the labels.
+	Approximate   bool          // Approximate is set if the counts are based on totals and exclusions were not applied.
+	MatchedWhen   bool          // MatchedWhen is set if Entry was selected by its when expression.
+	WhenErr       error         // WhenErr holds the errors of when expressions that failed to evaluate.
+	Scopes        []ScopeResult // Scopes holds the sizes of the scopes with changed files.
+}
+
+// Calculate calculates the size of the changed files and the matching configuration entry.
+// Files matching exclude_files or marked generated or vendored by the attributes do not count towards the size.
+// The number of commits is not known from the files and counts as zero, see CalculateWithCommits.
+func Calculate(files []FileChange, config Config, attributes LinguistAttributes) Result {
+	return CalculateWithCommits(files, 0, config, attributes)
+}
+
+// CalculateWithCommits calculates the size of the changed files made by the given number of commits.
+func CalculateWithCommits(files []FileChange, commits int, config Config, attributes LinguistAttributes) Result {
+	counts := CountChanges(files, config, attributes)
+	counts.Commits = commits
+	result := NewResultFromCounts(counts, config)
+	result.ExcludedFiles = ExcludedFiles(files, config, attributes)
+
+	// The first entry matching its when expression wins, the thresholds are the fallback
+	entry, matched, err := MatchWhen(config.LabelConfigs, NewWhenEnv(files, counts, config, attributes))
+	if matched {
+		result.Entry, result.MatchedWhen = entry, true
+	}
+	result.WhenErr = err
+	result.Scopes = CalculateScopes(files, commits, config, attributes)
+	return result
+}
+
+// NewResult maps the number of files and lines to the matching configuration entries.
+func NewResult(numberOfFiles, numberOfLines int, config Config) Result {
+	return NewResultFromCounts(Counts{Files: numberOfFiles, Lines: numberOfLines}, config)
+}
+
+// NewResultFromCounts maps the counts of all dimensions to the matching configuration entries.
+func NewResultFromCounts(counts Counts, config Config) Result {
+	size, diff := MapNumberOfChangesToSize(counts.Files, counts.Lines, config)
+	return Result{
+		Counts:     counts,
+		FilesEntry: size,
+		DiffEntry:  diff,
+		Entry:      SelectEntry(config.LabelConfigs, counts),
+	}
+}
+
+// EntryFor returns the entry matching the count of a single dimension.
+func (r Result) EntryFor(labelConfigs []ConfigEntry, paramName string) ConfigEntry {
+	switch paramName {
+	case ParamNameFiles:
+		return r.FilesEntry
+	case ParamNameDiff:
+		return r.DiffEntry
+	}
+	return GetSize(labelConfigs, r.Count(paramName), paramName)
+}
+
+// DecidedBy returns which parameters decided the size, the configured dimensions matching the biggest entry,
+// or the when expression if it selected the entry.
+func (r Result) DecidedBy(labelConfigs []ConfigEntry) string {
+	if r.MatchedWhen {
+		return "the when expression of size " + r.Entry.Size
+	}
+
+	var params []string
+	biggest := -1
+	for _, paramName := range ParamNames {
+		if paramName != ParamNameFiles && paramName != ParamNameDiff && !IsParamConfigured(labelConfigs, paramName) {
+			continue
+		}
+
+		index := FindConfigEntryIndex(labelConfigs, r.EntryFor(labelConfigs, paramName).Size)
+		switch {
+		case index > biggest:
+			params, biggest = []string{paramName}, index
+		case index == biggest:
+			params = append(params, paramName)
+		}
+	}
+
+	if len(params) < 2 {
+		return strings.Join(params, "")
+	}
+	return strings.Join(params[:len(params)-1], ", ") + " and " + params[len(params)-1]
+}
+
+// CalculateSizeAndDiff calculates the weighted size and diff for the changed files.
+// Files matching exclude_files or marked generated or vendored by the attributes are skipped.
+func CalculateSizeAndDiff(files []FileChange, config Config, attributes LinguistAttributes) (int, int) {
+	numberOfFiles, numberOfLines := 0.0, 0.0
+	for _, file := range files {
+		if config.AddedLinesOnly && file.Status == StatusRemoved {
+			continue
+		}
+
+		if !IsExcluded(file.Filename, config, attributes) {
+			weight := config.Weights.WeightFor(file.Filename)
+			numberOfFiles += weight
+
+			if config.AddedLinesOnly {
+				numberOfLines += weight * float64(file.Additions)
+			} else {
+				numberOfLines += weight * float64(file.Changes)
+			}
+		}
+	}
+	return int(math.Round(numberOfFiles)), int(math.Round(numberOfLines))
+}
+
+// IsExcluded checks if a file is excluded by exclude_files or marked generated or vendored by the attributes.
+func IsExcluded(filename string, config Config, attributes LinguistAttributes) bool {
+	return ShouldExcludeFile(filename, config.ExcludeFiles) || attributes.Excludes(filename)
+}
+
+// ExcludedFiles returns the names of the files that do not count towards the size.
+func ExcludedFiles(files []FileChange, config Config, attributes LinguistAttributes) []string {
+	var excluded []string
+	for _, file := range files {
+		if IsExcluded(file.Filename, config, attributes) {
+			excluded = append(excluded, file.Filename)
+		}
+	}
+	return excluded
+}
+
+// MapNumberOfChangesToSize maps the number of files and lines to their configuration entries.
+func MapNumberOfChangesToSize(numberOfFiles, numberOfLines int, config Config) (ConfigEntry, ConfigEntry) {
+	size := GetSize(config.LabelConfigs, numberOfFiles, ParamNameFiles)
+	diff := GetSize(config.LabelConfigs, numberOfLines, ParamNameDiff)
+	return size, diff
+}
+
+// GetSize retrieves the size configuration based on the count of a single dimension.
+// Entries not limiting the dimension match any count.
+func GetSize(configuration []ConfigEntry, currentCount int, paramName string) ConfigEntry {
+	if len(configuration) == 0 {
+		return ConfigEntry{}
+	}
+
+	for _, entry := range configuration {
+		threshold, ok := entry.Threshold(paramName)
+		if !ok || currentCount <= threshold {
+			return entry
+		}
+	}
+	return configuration[len(configuration)-1]
+}
+
+// GetBiggestEntry determines the largest entry between two ConfigEntry objects based on the user-defined order.
+func GetBiggestEntry(configEntries []ConfigEntry, size, diff ConfigEntry) ConfigEntry {
+	sizeIndex := FindConfigEntryIndex(configEntries, size.Size)
+	diffIndex := FindConfigEntryIndex(configEntries, diff.Size)
+
+	if sizeIndex >= diffIndex {
+		return size
+	}
+	return diff
+}
+
+// FindConfigEntryIndex finds the index of a ConfigEntry in the configuration based on size.
+func FindConfigEntryIndex(entries []ConfigEntry, size string) int {
+	for i, entry := range entries {
+		if entry.Size == size {
+			return i
+		}
+	}
+	return -1
+}
+
+// CrossedThreshold returns the threshold of the entry preceding the given one, which the count exceeded.
+// It returns false if the entry is the smallest one or the preceding entry does not limit the dimension.
+func CrossedThreshold(labelConfigs []ConfigEntry, entry ConfigEntry, paramName string) (int, bool) {
+	index := FindConfigEntryIndex(labelConfigs, entry.Size)
+	if index <= 0 {
+		return 0, false
+	}
+	return labelConfigs[index-1].Threshold(paramName)
+}
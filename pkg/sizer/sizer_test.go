@@ -0,0 +1,386 @@
+package sizer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMapSizeAndDiff(t *testing.T) {
+	// Define the configuration separately for clarity
+	xsConfig := ConfigEntry{Size: "xs", Diff: 10, Files: 1, Labels: []string{"size/xs"}}
+	sConfig := ConfigEntry{Size: "s", Diff: 50, Files: 10, Labels: []string{"size/s"}}
+	mConfig := ConfigEntry{Size: "m", Diff: 100, Files: 20, Labels: []string{"size/m"}}
+	lConfig := ConfigEntry{Size: "l", Diff: 500, Files: 50, Labels: []string{"size/l"}}
+	xlConfig := ConfigEntry{Size: "xl", Diff: 1000, Files: 100, Labels: []string{"size/xl"}}
+
+	config := Config{
+		ExcludeFiles: []string{"exclude.*"},
+		LabelConfigs: []ConfigEntry{xsConfig, sConfig, mConfig, lConfig, xlConfig},
+	}
+
+	tests := []struct {
+		name     string
+		files    []FileChange
+		config   Config
+		wantSize ConfigEntry
+		wantDiff ConfigEntry
+	}{
+		{
+			"No files should result in the smallest size and diff",
+			[]FileChange{},
+			config,
+			xsConfig,
+			xsConfig,
+		},
+		{
+			"Files within 'small' thresholds",
+			[]FileChange{
+				mockFileChange("file1.go", "added", 5, 5),
+				mockFileChange("file2.go", "modified", 10, 8),
+			},
+			config,
+			sConfig,
+			sConfig,
+		},
+		{
+			"Files exceeding 'small' but within 'medium' thresholds",
+			[]FileChange{
+				mockFileChange("file1.go", "modified", 30, 20),
+				mockFileChange("file2.go", "modified", 70, 60),
+			},
+			config,
+			sConfig,
+			mConfig,
+		},
+		{
+			"Files with one excluded file",
+			[]FileChange{
+				mockFileChange("exclude.txt", "modified", 100, 90),
+				mockFileChange("file2.go", "modified", 20, 10),
+			},
+			config,
+			xsConfig,
+			sConfig,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			numberOfFiles, numberOfLines := CalculateSizeAndDiff(tt.files, tt.config, nil)
+			gotSize, gotDiff := MapNumberOfChangesToSize(numberOfFiles, numberOfLines, tt.config)
+			if !configEntriesAreEqual(gotSize, tt.wantSize) || !configEntriesAreEqual(gotDiff, tt.wantDiff) {
+				t.Errorf("CalculateSizeAndDiff() = size: %v, diff: %v, want size: %v, want diff: %v", gotSize, gotDiff, tt.wantSize, tt.wantDiff)
+			}
+		})
+	}
+}
+
+func TestCalculateSizeAndDiff(t *testing.T) {
+	configDefault := Config{
+		ExcludeFiles:   []string{"exclude.*"},
+		LabelConfigs:   []ConfigEntry{},
+		AddedLinesOnly: false,
+	}
+
+	configAddedLinesOnly := Config{
+		ExcludeFiles:   []string{"exclude.*"},
+		LabelConfigs:   []ConfigEntry{},
+		AddedLinesOnly: true,
+	}
+
+	configWeighted := Config{
+		ExcludeFiles: []string{"exclude.*"},
+		LabelConfigs: []ConfigEntry{},
+		Weights: Weights{
+			{Pattern: "docs/*", Weight: 0.25},
+			{Pattern: "*_test.go", Weight: 0.5},
+			{Pattern: "migrations/*", Weight: 2.0},
+		},
+	}
+
+	tests := []struct {
+		name              string
+		files             []FileChange
+		config            Config
+		wantNumberOfFiles int
+		wantNumberOfLines int
+	}{
+		{
+			"No files should return 0 for everything",
+			[]FileChange{},
+			configDefault,
+			0,
+			0,
+		},
+		{
+			"No files should return 0 for everything with added lines only",
+			[]FileChange{},
+			configAddedLinesOnly,
+			0,
+			0,
+		},
+		{
+			"Files with an excluded file",
+			[]FileChange{
+				mockFileChange("exclude.txt", "modified", 100, 90),
+				mockFileChange("file2.go", "modified", 20, 10),
+			},
+			configDefault,
+			1,
+			20,
+		},
+		{
+			"Files with an excluded file with added lines only",
+			[]FileChange{
+				mockFileChange("exclude.txt", "modified", 100, 90),
+				mockFileChange("file2.go", "modified", 20, 10),
+			},
+			configAddedLinesOnly,
+			1,
+			10,
+		},
+		{
+			"A deleted file should not count towards the size",
+			[]FileChange{
+				mockFileChange("file1.go", "removed", 50, 0),
+				mockFileChange("file2.go", "modified", 30, 25),
+				mockFileChange("file3.go", "modified", 40, 35),
+			},
+			configAddedLinesOnly,
+			2,
+			60,
+		},
+		{
+			"A modified file with only deletions should still count towards the number of files",
+			[]FileChange{
+				mockFileChange("file2.go", "modified", 30, 0),
+				mockFileChange("file3.go", "modified", 40, 35),
+			},
+			configAddedLinesOnly,
+			2,
+			35,
+		},
+		{
+			"Weighted files count less or more towards the size",
+			[]FileChange{
+				mockFileChange("docs/readme.md", "modified", 100, 80),
+				mockFileChange("main_test.go", "modified", 40, 30),
+				mockFileChange("migrations/001.sql", "added", 10, 10),
+				mockFileChange("main.go", "modified", 20, 10),
+				mockFileChange("exclude.txt", "modified", 100, 90),
+			},
+			configWeighted,
+			4,
+			85,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotNumberOfFiles, gotNumberOfLines := CalculateSizeAndDiff(tt.files, tt.config, nil)
+			if gotNumberOfFiles != tt.wantNumberOfFiles || gotNumberOfLines != tt.wantNumberOfLines {
+				t.Errorf("CalculateSizeAndDiff() = files: %d, want files: %d, lines: %d, want lines: %d", gotNumberOfFiles, tt.wantNumberOfFiles, gotNumberOfLines, tt.wantNumberOfLines)
+			}
+		})
+	}
+}
+
+func TestExcludedFiles(t *testing.T) {
+	config := Config{ExcludeFiles: []string{"exclude.*", "vendor/**"}}
+	files := []FileChange{
+		mockFileChange("exclude.txt", "modified", 100, 90),
+		mockFileChange("main.go", "modified", 20, 10),
+		mockFileChange("vendor/lib/lib.go", "added", 50, 50),
+	}
+
+	want := []string{"exclude.txt", "vendor/lib/lib.go"}
+	if got := ExcludedFiles(files, config, nil); !reflect.DeepEqual(got, want) {
+		t.Errorf("ExcludedFiles() = %v, want %v", got, want)
+	}
+}
+
+func TestGetSize(t *testing.T) {
+	// Define configuration entries for clarity
+	xsConfig := ConfigEntry{Size: "xs", Diff: 10, Files: 1, Labels: []string{"size/xs"}}
+	sConfig := ConfigEntry{Size: "s", Diff: 50, Files: 10, Labels: []string{"size/s"}}
+	mConfig := ConfigEntry{Size: "m", Diff: 100, Files: 20, Labels: []string{"size/m"}}
+	lConfig := ConfigEntry{Size: "l", Diff: 500, Files: 50, Labels: []string{"size/l"}}
+	xlConfig := ConfigEntry{Size: "xl", Diff: 1000, Files: 100, Labels: []string{"size/xl"}}
+
+	configuration := []ConfigEntry{xsConfig, sConfig, mConfig, lConfig, xlConfig}
+
+	tests := []struct {
+		name          string
+		configuration []ConfigEntry
+		currentCount  int
+		paramName     string
+		want          ConfigEntry
+	}{
+		// Tests for file count
+		{"Fewer files than XS threshold", configuration, 0, ParamNameFiles, xsConfig},
+		{"Files equal to S threshold", configuration, 10, ParamNameFiles, sConfig},
+		{"Files between S and M thresholds", configuration, 15, ParamNameFiles, mConfig},
+		{"More files than XL threshold", configuration, 105, ParamNameFiles, xlConfig},
+
+		// Tests for diff count
+		{"Fewer changes than XS threshold", configuration, 5, ParamNameDiff, xsConfig},
+		{"Changes equal to M threshold", configuration, 100, ParamNameDiff, mConfig},
+		{"Changes between S and M thresholds", configuration, 35, ParamNameDiff, sConfig},
+		{"More changes than XL threshold", configuration, 1500, ParamNameDiff, xlConfig},
+
+		// Tests for an empty configuration
+		{"Empty configuration", []ConfigEntry{}, 5, ParamNameDiff, ConfigEntry{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := GetSize(tt.configuration, tt.currentCount, tt.paramName)
+			if !configEntriesAreEqual(got, tt.want) {
+				t.Errorf("GetSize() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetBiggestEntry(t *testing.T) {
+	entries := []ConfigEntry{
+		{Size: "small", Diff: 10, Files: 1, Labels: []string{"label1"}},
+		{Size: "medium", Diff: 20, Files: 2, Labels: []string{"label2"}},
+		{Size: "large", Diff: 30, Files: 3, Labels: []string{"label3"}},
+	}
+
+	tests := []struct {
+		name    string
+		entries []ConfigEntry
+		size    ConfigEntry
+		diff    ConfigEntry
+		want    ConfigEntry
+	}{
+		{"SizeLarger", entries, entries[2], entries[1], entries[2]},
+		{"DiffLarger", entries, entries[0], entries[2], entries[2]},
+		{"EqualSizeDiff", entries, entries[1], entries[1], entries[1]},
+		{"SizeNotInConfig", entries, ConfigEntry{Size: "xlarge", Diff: 40, Files: 4, Labels: []string{"label4"}}, entries[1], entries[1]},
+		{"DiffNotInConfig", entries, entries[1], ConfigEntry{Size: "xlarge", Diff: 40, Files: 4, Labels: []string{"label4"}}, entries[1]},
+		{
+			"BothNotInConfig", entries,
+			ConfigEntry{Size: "xlarge", Diff: 40, Files: 4, Labels: []string{"label4"}},
+			ConfigEntry{Size: "xxlarge", Diff: 50, Files: 5, Labels: []string{"label5"}},
+			ConfigEntry{Size: "xlarge", Diff: 40, Files: 4, Labels: []string{"label4"}},
+		}, // Expecting `size` to be returned
+		{"SingleEntryConfig", []ConfigEntry{{Size: "single", Diff: 10, Files: 1, Labels: []string{"label1"}}}, ConfigEntry{Size: "single", Diff: 10, Files: 1, Labels: []string{"label1"}}, ConfigEntry{Size: "single", Diff: 10, Files: 1, Labels: []string{"label1"}}, ConfigEntry{Size: "single", Diff: 10, Files: 1, Labels: []string{"label1"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := GetBiggestEntry(tt.entries, tt.size, tt.diff)
+			if !configEntriesAreEqual(got, tt.want) {
+				t.Errorf("GetBiggestEntry() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindConfigEntryIndex(t *testing.T) {
+	entries := []ConfigEntry{
+		{Size: "small", Diff: 10, Files: 1, Labels: []string{"label1"}},
+		{Size: "medium", Diff: 20, Files: 2, Labels: []string{"label2"}},
+		{Size: "large", Diff: 30, Files: 3, Labels: []string{"label3"}},
+	}
+
+	tests := []struct {
+		name    string
+		entries []ConfigEntry
+		size    string
+		want    int
+	}{
+		{"SizeAtBeginning", entries, "small", 0},
+		{"SizeAtEnd", entries, "large", 2},
+		{"SizeInMiddle", entries, "medium", 1},
+		{"SizeNotExists", entries, "extra-large", -1},
+		{"SingleEntryMatch", []ConfigEntry{{Size: "single", Diff: 10, Files: 1, Labels: []string{"label1"}}}, "single", 0},
+		{"SingleEntryNoMatch", []ConfigEntry{{Size: "single", Diff: 10, Files: 1, Labels: []string{"label1"}}}, "double", -1},
+		{"EmptyList", []ConfigEntry{}, "any", -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FindConfigEntryIndex(tt.entries, tt.size); got != tt.want {
+				t.Errorf("FindConfigEntryIndex() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func configEntriesAreEqual(a, b ConfigEntry) bool {
+	if a.Size != b.Size || a.Diff != b.Diff || a.Files != b.Files {
+		return false
+	}
+	if len(a.Labels) != len(b.Labels) {
+		return false
+	}
+	for i, label := range a.Labels {
+		if label != b.Labels[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func mockFileChange(filename string, status string, changes int, additions int) FileChange {
+	return FileChange{
+		Filename:  filename,
+		Status:    status,
+		Changes:   changes,
+		Additions: additions,
+	}
+}
+
+var resultLabelConfigs = []ConfigEntry{
+	{Size: "xs", Diff: 10, Files: 1, Labels: []string{"size/xs"}},
+	{Size: "s", Diff: 50, Files: 10, Labels: []string{"size/s"}},
+	{Size: "m", Diff: 100, Files: 20, Labels: []string{"size/m", "pairing-wanted"}},
+}
+
+func TestResultDecidedBy(t *testing.T) {
+	tests := []struct {
+		name   string
+		result Result
+		want   string
+	}{
+		{"Files", Result{FilesEntry: resultLabelConfigs[2], DiffEntry: resultLabelConfigs[1]}, "files"},
+		{"Diff", Result{FilesEntry: resultLabelConfigs[0], DiffEntry: resultLabelConfigs[1]}, "diff"},
+		{"Both", Result{FilesEntry: resultLabelConfigs[1], DiffEntry: resultLabelConfigs[1]}, "files and diff"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.result.DecidedBy(resultLabelConfigs); got != tt.want {
+				t.Errorf("DecidedBy() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCrossedThreshold(t *testing.T) {
+	tests := []struct {
+		name      string
+		entry     ConfigEntry
+		paramName string
+		want      int
+		wantOk    bool
+	}{
+		{"SmallestEntry", resultLabelConfigs[0], ParamNameDiff, 0, false},
+		{"DiffOfPreviousEntry", resultLabelConfigs[2], ParamNameDiff, 50, true},
+		{"FilesOfPreviousEntry", resultLabelConfigs[1], ParamNameFiles, 1, true},
+		{"UnknownEntry", ConfigEntry{Size: "xl"}, ParamNameFiles, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotOk := CrossedThreshold(resultLabelConfigs, tt.entry, tt.paramName)
+			if got != tt.want || gotOk != tt.wantOk {
+				t.Errorf("CrossedThreshold() = %v, %v, want %v, %v", got, gotOk, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package sizer
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// WeightRule assigns a multiplier to the files matching a pattern.
+type WeightRule struct {
+	Pattern string
+	Weight  float64
+}
+
+// Weights holds the weighting rules in the order they are defined in the configuration.
+type Weights []WeightRule
+
+// UnmarshalYAML decodes a mapping of patterns to multipliers while preserving its order.
+func (w *Weights) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return &yaml.TypeError{Errors: []string{fmt.Sprintf("line %d: weights must be a mapping of patterns to multipliers", node.Line)}}
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		var weight float64
+		if err := node.Content[i+1].Decode(&weight); err != nil {
+			return err
+		}
+		*w = append(*w, WeightRule{Pattern: node.Content[i].Value, Weight: weight})
+	}
+	return nil
+}
+
+// WeightFor returns the multiplier of the first rule matching the filename, or 1 if none matches.
+func (w Weights) WeightFor(filename string) float64 {
+	for _, rule := range w {
+		if MatchesPattern(filename, rule.Pattern) {
+			return rule.Weight
+		}
+	}
+	return 1
+}
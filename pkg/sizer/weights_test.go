@@ -0,0 +1,55 @@
+package sizer
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestWeightsUnmarshalYAML(t *testing.T) {
+	data := []byte("weights:\n  docs/*: 0.25\n  \"*_test.go\": 0.5\n  migrations/*: 2\n")
+
+	var config Config
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		t.Fatalf("yaml.Unmarshal() error = %v", err)
+	}
+
+	want := Weights{
+		{Pattern: "docs/*", Weight: 0.25},
+		{Pattern: "*_test.go", Weight: 0.5},
+		{Pattern: "migrations/*", Weight: 2},
+	}
+	if !reflect.DeepEqual(config.Weights, want) {
+		t.Errorf("Weights = %v, want %v", config.Weights, want)
+	}
+
+	if err := yaml.Unmarshal([]byte("weights: [docs/*]\n"), &config); err == nil {
+		t.Errorf("yaml.Unmarshal() expected an error for a weights sequence")
+	}
+}
+
+func TestWeightFor(t *testing.T) {
+	weights := Weights{
+		{Pattern: "docs/*", Weight: 0.25},
+		{Pattern: "*.md", Weight: 0.5},
+	}
+
+	tests := []struct {
+		name     string
+		filename string
+		want     float64
+	}{
+		{"FirstMatchingRuleWins", "docs/readme.md", 0.25},
+		{"SecondRuleMatches", "README.md", 0.5},
+		{"NoRuleMatches", "main.go", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := weights.WeightFor(tt.filename); got != tt.want {
+				t.Errorf("WeightFor(%v) = %v, want %v", tt.filename, got, tt.want)
+			}
+		})
+	}
+}
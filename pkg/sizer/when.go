@@ -0,0 +1,107 @@
+package sizer
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/expr-lang/expr"
+	"github.com/expr-lang/expr/vm"
+)
+
+// WhenChange describes a changed file in the context of a when expression.
+// The counts are not weighted.
+type WhenChange struct {
+	Path      string `expr:"path"`
+	Status    string `expr:"status"`
+	Additions int    `expr:"additions"`
+	Deletions int    `expr:"deletions"`
+	Lines     int    `expr:"lines"`    // Lines is the number of changed lines counted towards the size.
+	Excluded  bool   `expr:"excluded"` // Excluded is set if the file does not count towards the size.
+}
+
+// WhenEnv is the context when expressions are evaluated against.
+// The totals only include the files counting towards the size and are weighted like the size.
+type WhenEnv struct {
+	Lines     int          `expr:"lines"`
+	Files     int          `expr:"files"`
+	Additions int          `expr:"additions"`
+	Deletions int          `expr:"deletions"`
+	Commits   int          `expr:"commits"`
+	Paths     []string     `expr:"paths"`   // Paths lists all changed files, including excluded ones.
+	Changes   []WhenChange `expr:"changes"` // Changes lists all changed files, including excluded ones.
+}
+
+// globFunction makes MatchesPattern available to expressions as glob(path, pattern).
+var globFunction = expr.Function("glob", func(params ...any) (any, error) {
+	return MatchesPattern(params[0].(string), params[1].(string)), nil
+}, new(func(string, string) bool))
+
+// CompileWhen compiles a when expression, which must evaluate to a boolean.
+// Expressions can only read the WhenEnv and call pure functions, so they can not have side effects.
+func CompileWhen(expression string) (*vm.Program, error) {
+	return expr.Compile(expression, expr.Env(WhenEnv{}), expr.AsBool(), globFunction)
+}
+
+// NewWhenEnv creates the context of when expressions for the changed files and their counts.
+func NewWhenEnv(files []FileChange, counts Counts, config Config, attributes LinguistAttributes) WhenEnv {
+	env := WhenEnv{
+		Lines:     counts.Lines,
+		Files:     counts.Files,
+		Deletions: counts.Deletions,
+		Commits:   counts.Commits,
+		Paths:     make([]string, 0, len(files)),
+		Changes:   make([]WhenChange, 0, len(files)),
+	}
+
+	additions := 0.0
+	for _, file := range files {
+		excluded := IsExcluded(file.Filename, config, attributes)
+		lines := file.Changes
+		if config.AddedLinesOnly {
+			lines = file.Additions
+		}
+
+		env.Paths = append(env.Paths, file.Filename)
+		env.Changes = append(env.Changes, WhenChange{
+			Path:      file.Filename,
+			Status:    file.Status,
+			Additions: file.Additions,
+			Deletions: file.Deletions,
+			Lines:     lines,
+			Excluded:  excluded,
+		})
+
+		if !excluded && (!config.AddedLinesOnly || file.Status != StatusRemoved) {
+			additions += config.Weights.WeightFor(file.Filename) * float64(file.Additions)
+		}
+	}
+	env.Additions = int(math.Round(additions))
+	return env
+}
+
+// MatchWhen returns the first entry whose when expression matches the context.
+// Entries failing to compile or evaluate do not match, their errors are returned joined.
+func MatchWhen(labelConfigs []ConfigEntry, env WhenEnv) (ConfigEntry, bool, error) {
+	var errs []error
+	for _, entry := range labelConfigs {
+		if entry.When == "" {
+			continue
+		}
+
+		program, err := CompileWhen(entry.When)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("size %q: %w", entry.Size, err))
+			continue
+		}
+		matched, err := expr.Run(program, env)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("size %q: %w", entry.Size, err))
+			continue
+		}
+		if matched.(bool) {
+			return entry, true, errors.Join(errs...)
+		}
+	}
+	return ConfigEntry{}, false, errors.Join(errs...)
+}
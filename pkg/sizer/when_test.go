@@ -0,0 +1,114 @@
+package sizer
+
+import (
+	"testing"
+)
+
+func TestCompileWhen(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		wantErr    bool
+	}{
+		{"Comparison", "lines > 500", false},
+		{"Glob", `any(paths, glob(#, "db/migrations/**"))`, false},
+		{"Changes", `sum(filter(changes, !glob(.path, "**/*_test.go")), .lines) > 500`, false},
+		{"UnknownVariable", "commentz > 1", true},
+		{"NotBoolean", "lines + 1", true},
+		{"SyntaxError", "lines >", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := CompileWhen(tt.expression); (err != nil) != tt.wantErr {
+				t.Errorf("CompileWhen() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCalculateWithWhen(t *testing.T) {
+	config := Config{
+		ExcludeFiles: []string{"go.sum"},
+		LabelConfigs: []ConfigEntry{
+			{Size: "s", Diff: 100, Files: 10, Labels: []string{"size/s"}},
+			{Size: "m", Diff: 1000, Files: 50, Labels: []string{"size/m"}},
+			{Size: "xl", Diff: 5000, Files: 100, Labels: []string{"size/xl"}, When: `sum(filter(changes, !glob(.path, "**/*_test.go")), .lines) > 500 || any(paths, glob(#, "db/migrations/**"))`},
+		},
+	}
+
+	tests := []struct {
+		name            string
+		files           []FileChange
+		want            string
+		wantMatchedWhen bool
+	}{
+		{
+			"ThresholdsAsFallback",
+			[]FileChange{{Filename: "main.go", Status: "modified", Additions: 80, Deletions: 40, Changes: 120}},
+			"m",
+			false,
+		},
+		{
+			"MigrationTouched",
+			[]FileChange{{Filename: "db/migrations/001_init.sql", Status: "added", Additions: 5, Changes: 5}},
+			"xl",
+			true,
+		},
+		{
+			"LinesOutsideTests",
+			[]FileChange{
+				{Filename: "main.go", Status: "modified", Additions: 300, Deletions: 300, Changes: 600},
+				{Filename: "main_test.go", Status: "modified", Additions: 100, Changes: 100},
+			},
+			"xl",
+			true,
+		},
+		{
+			"LinesInTests",
+			[]FileChange{{Filename: "main_test.go", Status: "modified", Additions: 600, Changes: 600}},
+			"m",
+			false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Calculate(tt.files, config, nil)
+			if result.Entry.Size != tt.want || result.MatchedWhen != tt.wantMatchedWhen || result.WhenErr != nil {
+				t.Errorf("Calculate() = size %v, matched when %v, error %v, want size %v, matched when %v", result.Entry.Size, result.MatchedWhen, result.WhenErr, tt.want, tt.wantMatchedWhen)
+			}
+		})
+	}
+}
+
+func TestNewWhenEnv(t *testing.T) {
+	files := []FileChange{
+		{Filename: "main.go", Status: "modified", Additions: 10, Deletions: 5, Changes: 15},
+		{Filename: "go.sum", Status: "modified", Additions: 20, Deletions: 20, Changes: 40},
+	}
+	config := Config{ExcludeFiles: []string{"go.sum"}}
+	counts := CountChanges(files, config, nil)
+	counts.Commits = 2
+
+	env := NewWhenEnv(files, counts, config, nil)
+	if env.Lines != 15 || env.Files != 1 || env.Additions != 10 || env.Deletions != 5 || env.Commits != 2 {
+		t.Errorf("NewWhenEnv() totals = %+v, want the totals of main.go and 2 commits", env)
+	}
+	if len(env.Paths) != 2 || len(env.Changes) != 2 || !env.Changes[1].Excluded {
+		t.Errorf("NewWhenEnv() = %+v, want both files with go.sum excluded", env)
+	}
+}
+
+func TestMatchWhenError(t *testing.T) {
+	labelConfigs := []ConfigEntry{
+		{Size: "s", When: "changes[3].lines > 10"},
+		{Size: "m", When: "lines >"},
+		{Size: "l", When: "lines > 1"},
+	}
+
+	got, matched, err := MatchWhen(labelConfigs, WhenEnv{Lines: 5})
+	if !matched || got.Size != "l" || err == nil {
+		t.Errorf("MatchWhen() = %v, %v, %v, want size l with the errors of sizes s and m", got.Size, matched, err)
+	}
+}